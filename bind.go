@@ -0,0 +1,331 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//BindError 请求数据绑定失败时返回的错误
+//Source为数据来源 json/xml/query/form/header/path
+//Field为出错的字段名 Value为无法转换的原始数据 无法确定时为空
+type BindError struct {
+	Source string
+	Field  string
+	Value  string
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	s := "bind " + e.Source
+	if e.Field != "" {
+		s += " " + e.Field
+	}
+	if e.Value != "" {
+		s += ": invalid value " + strconv.Quote(e.Value)
+	}
+	return s + ": " + e.Err.Error()
+}
+
+//Unwrap 返回原始错误
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+//Bind 根据请求的Content-Type选择解析方式 将请求体绑定到v中 绑定后按validate标签校验 参见 Validate
+//	application/json                  -> BindJSON
+//	application/xml text/xml          -> BindXML
+//	application/x-www-form-urlencoded -> BindForm
+//	multipart/form-data               -> BindForm 上传文件可绑定到 *multipart.FileHeader 或 []*multipart.FileHeader 字段
+//未设置Content-Type时按form标签绑定url参数 其他类型返回 *BindError
+func (c *Context) Bind(v interface{}) error {
+	return validateBound(c.bind(v), v)
+}
+
+//BindJSON 按json标签将JSON请求体绑定到v中并校验
+func (c *Context) BindJSON(v interface{}) error {
+	return validateBound(c.bindJSON(v), v)
+}
+
+//BindXML 按xml标签将XML请求体绑定到v中并校验
+func (c *Context) BindXML(v interface{}) error {
+	return validateBound(c.bindXML(v), v)
+}
+
+//BindQuery 按query标签将url参数绑定到结构体指针v中并校验
+func (c *Context) BindQuery(v interface{}) error {
+	return validateBound(c.bindQuery(v), v)
+}
+
+//BindForm 按form标签将url参数及表单绑定到结构体指针v中并校验
+func (c *Context) BindForm(v interface{}) error {
+	return validateBound(c.bindForm(v), v)
+}
+
+//BindHeader 按header标签将请求头绑定到结构体指针v中并校验 标签名不区分大小写
+func (c *Context) BindHeader(v interface{}) error {
+	return validateBound(c.bindHeader(v), v)
+}
+
+//BindPath 按path标签将路径参数绑定到结构体指针v中并校验
+func (c *Context) BindPath(v interface{}) error {
+	return validateBound(c.bindPath(v), v)
+}
+
+//validateBound 绑定成功后校验结构体
+func validateBound(err error, v interface{}) error {
+	if err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+//bind 根据请求的Content-Type选择解析方式 将请求体绑定到v中
+func (c *Context) bind(v interface{}) error {
+	ct := c.Request.Header.Get("Content-Type")
+	if ct == "" {
+		return c.bindForm(v)
+	}
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return &BindError{Source: "body", Err: err}
+	}
+	switch mediaType {
+	case "application/json":
+		return c.bindJSON(v)
+	case "application/xml", "text/xml":
+		return c.bindXML(v)
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return c.bindForm(v)
+	}
+	return &BindError{Source: "body", Err: errors.New("unsupported content type " + mediaType)}
+}
+
+//bindJSON 按json标签将JSON请求体绑定到v中 请求体为空时不做处理
+func (c *Context) bindJSON(v interface{}) error {
+	if c.Request.Body == nil {
+		return nil
+	}
+	err := json.NewDecoder(c.Request.Body).Decode(v)
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *json.UnmarshalTypeError:
+		return &BindError{Source: "json", Field: e.Field, Err: errors.New("cannot unmarshal " + e.Value + " into " + e.Type.String())}
+	case *json.SyntaxError:
+		return &BindError{Source: "json", Err: errors.New(e.Error() + " at offset " + strconv.FormatInt(e.Offset, 10))}
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return &BindError{Source: "json", Err: err}
+}
+
+//bindXML 按xml标签将XML请求体绑定到v中 请求体为空时不做处理
+func (c *Context) bindXML(v interface{}) error {
+	if c.Request.Body == nil {
+		return nil
+	}
+	if err := xml.NewDecoder(c.Request.Body).Decode(v); err != nil && err != io.EOF {
+		return &BindError{Source: "xml", Err: err}
+	}
+	return nil
+}
+
+//bindQuery 按query标签将url参数绑定到结构体指针v中
+func (c *Context) bindQuery(v interface{}) error {
+	query := c.Request.URL.Query()
+	return bindValues(v, "query", func(name string) []string { return query[name] }, nil)
+}
+
+//bindForm 按form标签将url参数及表单绑定到结构体指针v中
+//multipart/form-data 请求中的上传文件绑定到 *multipart.FileHeader 或 []*multipart.FileHeader 字段
+func (c *Context) bindForm(v interface{}) error {
+	r := c.Request
+	if err := c.parseForm(); err != nil {
+		return &BindError{Source: "form", Err: err}
+	}
+	var files map[string][]*multipart.FileHeader
+	if r.MultipartForm != nil {
+		files = r.MultipartForm.File
+	}
+	return bindValues(v, "form", func(name string) []string { return r.Form[name] }, files)
+}
+
+//bindHeader 按header标签将请求头绑定到结构体指针v中 标签名不区分大小写
+func (c *Context) bindHeader(v interface{}) error {
+	header := c.Request.Header
+	return bindValues(v, "header", func(name string) []string {
+		return header[textproto.CanonicalMIMEHeaderKey(name)]
+	}, nil)
+}
+
+//bindPath 按path标签将路径参数绑定到结构体指针v中
+func (c *Context) bindPath(v interface{}) error {
+	return bindValues(v, "path", func(name string) []string {
+		for _, p := range c.params {
+			if p.Key == name {
+				return []string{p.Value}
+			}
+		}
+		return nil
+	}, nil)
+}
+
+//bindRequest 将请求数据绑定到控制器方法的请求参数中
+//依次绑定 请求体(参见Bind) url参数(query标签) 路径参数(path标签) 全部绑定后再进行校验
+//同名数据以后绑定的为准 即 路径参数 > url参数 > 请求体
+func bindRequest(c *Context, v interface{}) error {
+	if err := c.bind(v); err != nil {
+		return err
+	}
+	if err := c.bindQuery(v); err != nil {
+		return err
+	}
+	if err := c.bindPath(v); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+//bindValues 按标签名将lookup取得的字符串数据绑定到结构体指针v的字段中
+//只绑定设置了标签的导出字段 标签为 - 的字段不绑定 匿名嵌入的结构体会展开绑定
+func bindValues(v interface{}, tag string, lookup func(string) []string, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("bind " + tag + ": target must be a non-nil pointer to struct")
+	}
+	return bindStruct(rv.Elem(), tag, lookup, files)
+}
+
+func bindStruct(sv reflect.Value, tag string, lookup func(string) []string, files map[string][]*multipart.FileHeader) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		fv := sv.Field(i)
+		name := field.Tag.Get(tag)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := bindStruct(fv, tag, lookup, files); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" || name == "" {
+			continue
+		}
+		if idx := strings.IndexByte(name, ','); idx >= 0 {
+			name = name[:idx]
+		}
+		switch field.Type {
+		case fileHeaderType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs[0]))
+			}
+			continue
+		case fileHeaderSliceType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs))
+			}
+			continue
+		}
+		vals := lookup(name)
+		if len(vals) == 0 {
+			continue
+		}
+		if val, err := setField(fv, vals); err != nil {
+			return &BindError{Source: tag, Field: name, Value: val, Err: err}
+		}
+	}
+	return nil
+}
+
+//setField 将字符串数据转换为字段类型后赋值 切片字段使用全部数据 其余字段使用第一个
+//失败时返回无法转换的数据
+func setField(fv reflect.Value, vals []string) (string, error) {
+	switch fv.Kind() {
+	case reflect.Ptr:
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setField(fv.Elem(), vals)
+	case reflect.Slice:
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(slice.Index(i), val); err != nil {
+				return val, err
+			}
+		}
+		fv.Set(slice)
+		return "", nil
+	}
+	return vals[0], setValue(fv, vals[0])
+}
+
+//setValue 将一个字符串转换为字段类型后赋值
+func setValue(fv reflect.Value, val string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		if val == "" {
+			val = "false"
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return parseError(fv, err)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return parseError(fv, err)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return parseError(fv, err)
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return parseError(fv, err)
+		}
+		fv.SetFloat(n)
+	case reflect.Ptr:
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setValue(fv.Elem(), val)
+	default:
+		return errors.New("unsupported field type " + fv.Type().String())
+	}
+	return nil
+}
+
+//parseError 生成字符串转换失败的错误 说明目标类型及原因
+func parseError(fv reflect.Value, err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		err = ne.Err
+	}
+	return errors.New("cannot parse as " + fv.Type().String() + ": " + err.Error())
+}
@@ -0,0 +1,133 @@
+package smile
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testBindUser struct {
+	ID     int      `path:"id"`
+	Name   string   `json:"name" xml:"name" form:"name"`
+	Age    uint8    `json:"age" xml:"age" form:"age"`
+	Tags   []string `form:"tag" query:"tag"`
+	Page   *int     `query:"page"`
+	Token  string   `header:"x-token"`
+	Secret string   `form:"-"`
+	testBindEmbed
+}
+
+type testBindEmbed struct {
+	Lang string `query:"lang" header:"Accept-Language"`
+}
+
+func newBindContext(method, url, contentType, body string) *Context {
+	r := httptest.NewRequest(method, url, strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return initContext(httptest.NewRecorder(), r, Default())
+}
+
+func TestBind(t *testing.T) {
+	var u testBindUser
+	c := newBindContext("POST", "/", "application/json; charset=utf-8", `{"name":"tom","age":18}`)
+	if err := c.Bind(&u); err != nil || u.Name != "tom" || u.Age != 18 {
+		t.Errorf("json: %+v %v", u, err)
+	}
+
+	u = testBindUser{}
+	c = newBindContext("POST", "/", "application/xml", `<user><name>ann</name><age>20</age></user>`)
+	if err := c.Bind(&u); err != nil || u.Name != "ann" || u.Age != 20 {
+		t.Errorf("xml: %+v %v", u, err)
+	}
+
+	u = testBindUser{}
+	c = newBindContext("POST", "/?tag=a", "application/x-www-form-urlencoded", "name=bob&tag=b&Secret=x")
+	if err := c.Bind(&u); err != nil || u.Name != "bob" || strings.Join(u.Tags, ",") != "b,a" || u.Secret != "" {
+		t.Errorf("form: %+v %v", u, err)
+	}
+
+	u = testBindUser{}
+	c = newBindContext("GET", "/?name=joe", "", "")
+	if err := c.Bind(&u); err != nil || u.Name != "joe" {
+		t.Errorf("no content type: %+v %v", u, err)
+	}
+
+	c = newBindContext("POST", "/", "text/plain", "name=bob")
+	var be *BindError
+	if err := c.Bind(&u); !errors.As(err, &be) || be.Source != "body" {
+		t.Errorf("unsupported content type: %v", err)
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("name", "tom")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		fw, _ := mw.CreateFormFile("files", name)
+		_, _ = fw.Write([]byte(name))
+	}
+	fw, _ := mw.CreateFormFile("avatar", "me.png")
+	_, _ = fw.Write([]byte("png"))
+	_ = mw.Close()
+
+	var v struct {
+		Name   string                  `form:"name"`
+		Avatar *multipart.FileHeader   `form:"avatar"`
+		Files  []*multipart.FileHeader `form:"files"`
+	}
+	c := newBindContext("POST", "/", mw.FormDataContentType(), body.String())
+	if err := c.Bind(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "tom" || v.Avatar == nil || v.Avatar.Filename != "me.png" || len(v.Files) != 2 || v.Files[1].Filename != "b.txt" {
+		t.Errorf("multipart: %+v", v)
+	}
+}
+
+func TestBindSources(t *testing.T) {
+	var u testBindUser
+	c := newBindContext("GET", "/users/7?page=2&tag=x&tag=y&lang=en", "", "")
+	c.Request.Header.Set("X-Token", "abc")
+	c.params = Params{{Key: "id", Value: "7"}}
+	if err := c.BindQuery(&u); err != nil || u.Page == nil || *u.Page != 2 || len(u.Tags) != 2 || u.Lang != "en" {
+		t.Errorf("query: %+v %v", u, err)
+	}
+	if err := c.BindPath(&u); err != nil || u.ID != 7 {
+		t.Errorf("path: %+v %v", u, err)
+	}
+	c.Request.Header.Set("Accept-Language", "zh")
+	if err := c.BindHeader(&u); err != nil || u.Token != "abc" || u.Lang != "zh" {
+		t.Errorf("header: %+v %v", u, err)
+	}
+	if err := c.BindQuery(u); err == nil {
+		t.Error("binding into a non-pointer should fail")
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	var u testBindUser
+	tests := []struct {
+		c    *Context
+		bind func(*Context, interface{}) error
+		want string
+	}{
+		{newBindContext("GET", "/?page=x", "", ""), (*Context).BindQuery, `bind query page: invalid value "x": cannot parse as int: invalid syntax`},
+		{newBindContext("POST", "/", "application/x-www-form-urlencoded", "age=300"), (*Context).BindForm, `bind form age: invalid value "300": cannot parse as uint8: value out of range`},
+		{newBindContext("POST", "/", "application/json", `{"age":"old"}`), (*Context).BindJSON, `bind json age: cannot unmarshal string into uint8`},
+		{newBindContext("POST", "/", "application/json", `{"age":`), (*Context).BindJSON, `bind json: unexpected EOF`},
+		{newBindContext("POST", "/", "application/json", `{"age" 1}`), (*Context).BindJSON, `bind json: invalid character '1' after object key at offset 8`},
+	}
+	for _, tt := range tests {
+		err := tt.bind(tt.c, &u)
+		var be *BindError
+		if !errors.As(err, &be) || err.Error() != tt.want {
+			t.Errorf("got %v, want %s", err, tt.want)
+		}
+	}
+}
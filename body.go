@@ -0,0 +1,189 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//BodyOptions 请求体解析配置
+//可以在服务器、路由组及路由上设置 生效顺序为 路由 > 路由组(由近及远) > 服务器
+//每个字段单独继承 为零值时使用上一级的配置
+type BodyOptions struct {
+	MaxMemory    int64 //解析multipart表单时使用的内存 超出部分存储在临时文件中 均未设置时使用 MaxFileSize
+	MaxBodySize  int64 //请求体的最大字节数 均未设置时不限制 超过时响应413 参见 RouteGroup.SetRoute413
+	DisableParse bool  //获取表单参数时不解析请求体 只能通过Bind系列方法或GetRawBody读取
+}
+
+//SetBodyOptions 设置服务器默认的请求体解析配置
+func (e *Engine) SetBodyOptions(opts BodyOptions) {
+	e.bodyOptions = &opts
+}
+
+//SetBodyOptions 设置路由组及下级路由组的请求体解析配置
+func (rg *RouteGroup) SetBodyOptions(opts BodyOptions) {
+	rg.bodyOptions = &opts
+}
+
+//BodyOptions 设置路由的请求体解析配置
+func (r *Route) BodyOptions(opts BodyOptions) *Route {
+	r.bodyOptions = &opts
+	return r
+}
+
+//resolveBodyOptions 按 路由 > 路由组 > 服务器 的顺序逐个字段选择生效的请求体解析配置
+//字段为零值时视为未设置 使用上一级的配置 如路由只设置DisableParse时仍受服务器的MaxBodySize限制
+func resolveBodyOptions(e *Engine, r *Route, group *RouteGroup) BodyOptions {
+	var levels []*BodyOptions
+	if r != nil {
+		levels = append(levels, r.bodyOptions)
+	}
+	for g := group; g != nil; g = g.parent {
+		levels = append(levels, g.bodyOptions)
+	}
+	if e != nil {
+		levels = append(levels, e.bodyOptions)
+	}
+	var opts BodyOptions
+	for _, o := range levels {
+		if o == nil {
+			continue
+		}
+		if opts.MaxMemory == 0 {
+			opts.MaxMemory = o.MaxMemory
+		}
+		if opts.MaxBodySize == 0 {
+			opts.MaxBodySize = o.MaxBodySize
+		}
+		opts.DisableParse = opts.DisableParse || o.DisableParse
+	}
+	return opts
+}
+
+//BodyTooLargeError 请求体超过 BodyOptions.MaxBodySize 时的错误
+//会记录在 Context.Errors() 中 读取请求体时也会返回该错误
+type BodyTooLargeError struct {
+	Limit int64 //请求体的最大字节数
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return "request body too large: limit " + strconv.FormatInt(e.Limit, 10) + " bytes"
+}
+
+//setBodyOptions 设置本次请求生效的请求体解析配置 并限制请求体大小
+//请求头Content-Length已超过限制时返回 *BodyTooLargeError
+func (c *Context) setBodyOptions(opts BodyOptions) error {
+	c.bodyOptions = opts
+	if opts.MaxBodySize <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	if c.Request.ContentLength > opts.MaxBodySize {
+		err := &BodyTooLargeError{Limit: opts.MaxBodySize}
+		c.bodyTooLarge(err)
+		return err
+	}
+	c.Request.Body = &limitedBody{c: c, rc: c.Request.Body, remaining: opts.MaxBodySize}
+	return nil
+}
+
+//bodyTooLarge 记录请求体过大的错误 剩余的请求体不再读取 响应后关闭连接
+func (c *Context) bodyTooLarge(err *BodyTooLargeError) {
+	c.errs = append(c.errs, err)
+	c.Header().Set("Connection", "close")
+}
+
+//bodyTooLargeError 返回本次请求记录的请求体过大的错误 没有时返回nil
+func (c *Context) bodyTooLargeError() *BodyTooLargeError {
+	for _, err := range c.errs {
+		if tooLarge, ok := err.(*BodyTooLargeError); ok {
+			return tooLarge
+		}
+	}
+	return nil
+}
+
+//bodyLimitHandler 读取请求体时超过大小限制(如chunked请求) 而业务方法忽略了错误且未写入响应时
+//使用路由组的413回调响应
+func bodyLimitHandler(fn HandlerFunc, group *RouteGroup) HandlerFunc {
+	return func(c *Context) error {
+		err := fn(c)
+		if err == nil && c.bodyTooLargeError() != nil && !c.ResponseWriter.(*responseWriter).isWritten() {
+			return group.handler413()(c)
+		}
+		return err
+	}
+}
+
+//limitedBody 限制可以读取的请求体大小
+//超过限制时返回 *BodyTooLargeError 而不是截断请求体
+type limitedBody struct {
+	c         *Context
+	rc        io.ReadCloser
+	remaining int64
+	err       error
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	//多读一个字节 用于判断是否超过限制
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.rc.Read(p)
+	if int64(n) <= l.remaining {
+		l.remaining -= int64(n)
+		return n, err
+	}
+	n = int(l.remaining)
+	l.remaining = 0
+	tooLarge := &BodyTooLargeError{Limit: l.c.bodyOptions.MaxBodySize}
+	l.err = tooLarge
+	l.c.bodyTooLarge(tooLarge)
+	return n, l.err
+}
+
+func (l *limitedBody) Close() error {
+	return l.rc.Close()
+}
+
+//parseForm 解析url参数及表单 multipart/form-data 请求同时解析上传文件
+//每次请求只解析一次 解析错误记录在 Errors() 中
+func (c *Context) parseForm() error {
+	if c.formParsed {
+		return c.formErr
+	}
+	c.formParsed = true
+	r := c.Request
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		maxMemory := c.bodyOptions.MaxMemory
+		if maxMemory <= 0 {
+			maxMemory = MaxFileSize
+		}
+		c.formErr = r.ParseMultipartForm(maxMemory)
+	} else {
+		c.formErr = r.ParseForm()
+	}
+	//请求体过大的错误已在读取时记录
+	var tooLarge *BodyTooLargeError
+	if c.formErr != nil && !errors.As(c.formErr, &tooLarge) {
+		c.errs = append(c.errs, c.formErr)
+	}
+	return c.formErr
+}
+
+//autoParseForm 获取表单参数前按需解析 DisableParse时不解析
+func (c *Context) autoParseForm() {
+	if !c.bodyOptions.DisableParse {
+		_ = c.parseForm()
+	}
+}
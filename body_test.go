@@ -0,0 +1,216 @@
+package smile
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestResolveBodyOptions(t *testing.T) {
+	e := Default()
+	rg := NewRouteGroup()
+	api := rg.Group("/api")
+	v1 := api.Group("/v1")
+	r := v1.SetGET("/a", func(c *Context) error { return nil })
+
+	if got := resolveBodyOptions(e, r, v1); got != (BodyOptions{}) {
+		t.Errorf("default %+v", got)
+	}
+	e.SetBodyOptions(BodyOptions{MaxBodySize: 1, MaxMemory: 64})
+	if got := resolveBodyOptions(e, r, v1); got.MaxBodySize != 1 {
+		t.Errorf("engine %+v", got)
+	}
+	api.SetBodyOptions(BodyOptions{MaxBodySize: 2})
+	if got := resolveBodyOptions(e, r, v1); got.MaxBodySize != 2 {
+		t.Errorf("parent group %+v", got)
+	}
+	v1.SetBodyOptions(BodyOptions{MaxBodySize: 3})
+	if got := resolveBodyOptions(e, r, v1); got.MaxBodySize != 3 {
+		t.Errorf("group %+v", got)
+	}
+	//只设置DisableParse的路由仍继承路由组及服务器的其他字段
+	r.BodyOptions(BodyOptions{DisableParse: true})
+	if got := resolveBodyOptions(e, r, v1); got.MaxBodySize != 3 || got.MaxMemory != 64 || !got.DisableParse {
+		t.Errorf("route %+v", got)
+	}
+	if got := resolveBodyOptions(e, nil, api); got.MaxBodySize != 2 || got.MaxMemory != 64 || got.DisableParse {
+		t.Errorf("other route %+v", got)
+	}
+}
+
+func TestLazyBodyParsing(t *testing.T) {
+	var raw, post, query string
+	var errs int
+	handler := func(c *Context) error {
+		raw = c.GetRawBody()
+		post = c.GetPostParam("name")
+		query = c.GetQueryParam("q")
+		errs = len(c.Errors())
+		return nil
+	}
+	rg := NewRouteGroup()
+	rg.SetPOST("/parse", func(c *Context) error {
+		post = c.GetPostParam("name")
+		query = c.GetQueryParam("q")
+		raw = c.GetRawBody()
+		errs = len(c.Errors())
+		return nil
+	})
+	rg.SetPOST("/raw", handler).BodyOptions(BodyOptions{DisableParse: true})
+	serve := func(url, contentType, body string) {
+		raw, post, query, errs = "", "", "", 0
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", url, strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		c := initContext(w, r, Default())
+		engine := createEngine(false).Init(c)
+		engine.Check(rg)
+		if err := engine.Handle(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	serve("/parse?q=1", "application/json", `{"name":"tom"}`)
+	if raw != `{"name":"tom"}` || post != "" || query != "1" || errs != 0 {
+		t.Errorf("json body should be left for the handler: raw %q post %q query %q errs %d", raw, post, query, errs)
+	}
+
+	serve("/raw?q=1", "application/x-www-form-urlencoded", "name=tom")
+	if raw != "name=tom" || post != "" || query != "1" {
+		t.Errorf("DisableParse: raw %q post %q query %q", raw, post, query)
+	}
+
+	serve("/parse?q=2", "application/x-www-form-urlencoded", "name=ann")
+	if post != "ann" || query != "2" || raw != "" || errs != 0 {
+		t.Errorf("lazy parse: raw %q post %q query %q errs %d", raw, post, query, errs)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	var called bool
+	rg := NewRouteGroup()
+	rg.SetPOST("/upload", func(c *Context) error {
+		called = true
+		if c.GetPostParam("name") == "" {
+			return c.Errors()[len(c.Errors())-1]
+		}
+		return c.Text(200, c.GetPostParam("name"))
+	}).BodyOptions(BodyOptions{MaxBodySize: 8})
+	//忽略读取请求体的错误且不写入响应
+	ignore := func(c *Context) error {
+		called = true
+		_ = c.GetPostParam("a")
+		return nil
+	}
+	rg.SetPOST("/ignore", ignore).BodyOptions(BodyOptions{MaxBodySize: 5})
+	api := rg.Group("/api")
+	api.SetBodyOptions(BodyOptions{MaxBodySize: 4})
+	api.SetRoute413(func(c *Context) error { return c.Text(413, "too big") })
+	api.SetPOST("/upload", func(c *Context) error {
+		called = true
+		return nil
+	})
+	api.SetPOST("/ignore", ignore)
+
+	serve := func(url, body string, chunked bool) (*httptest.ResponseRecorder, *Context) {
+		called = false
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", url, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if chunked {
+			r.ContentLength = -1
+		}
+		c := initContext(w, r, Default())
+		engine := createEngine(false).Init(c)
+		engine.Check(rg)
+		if err := engine.Handle(); err != nil {
+			doDebug(err, c)
+		}
+		c.Close()
+		return w, c
+	}
+	tooLarge := func(c *Context) bool {
+		n := 0
+		for _, err := range c.Errors() {
+			var e *BodyTooLargeError
+			if errors.As(err, &e) && e.Limit > 0 {
+				n++
+			}
+		}
+		return n == 1
+	}
+
+	w, c := serve("/upload", "name=tom", false)
+	if w.Code != 200 || w.Body.String() != "tom" || tooLarge(c) {
+		t.Errorf("within limit: code %d body %q", w.Code, w.Body.String())
+	}
+
+	w, c = serve("/upload", "name=tommy", false)
+	if called || w.Code != 413 || !tooLarge(c) || w.Header().Get("Connection") != "close" {
+		t.Errorf("content length over limit: called %v code %d", called, w.Code)
+	}
+
+	w, c = serve("/upload", "name=tommy", true)
+	if !called || w.Code != 413 || !tooLarge(c) {
+		t.Errorf("chunked body over limit: called %v code %d body %q", called, w.Code, w.Body.String())
+	}
+
+	w, _ = serve("/api/upload", "name=tom", false)
+	if called || w.Code != 413 || w.Body.String() != "too big" {
+		t.Errorf("group 413 handler: called %v code %d body %q", called, w.Code, w.Body.String())
+	}
+
+	w, c = serve("/ignore", "a=1234567890", true)
+	if !called || w.Code != 413 || !tooLarge(c) || !strings.Contains(w.Body.String(), "payload too large") {
+		t.Errorf("chunked body ignored by handler: called %v code %d body %q", called, w.Code, w.Body.String())
+	}
+
+	w, c = serve("/api/ignore", "a=1234567890", true)
+	if !called || w.Code != 413 || !tooLarge(c) || w.Body.String() != "too big" {
+		t.Errorf("chunked body ignored by handler with group 413: called %v code %d body %q", called, w.Code, w.Body.String())
+	}
+}
+
+func TestMultipartTempFilesRemoved(t *testing.T) {
+	dir := t.TempDir()
+	tmp := os.Getenv("TMPDIR")
+	os.Setenv("TMPDIR", dir)
+	defer os.Setenv("TMPDIR", tmp)
+
+	e := Default()
+	e.SetBodyOptions(BodyOptions{MaxMemory: 16})
+	e.RouteGroup.SetPOST("/upload", func(c *Context) error {
+		files := c.GetMultipartFormFile("file")
+		if len(files) != 1 {
+			return c.Text(400, "no file")
+		}
+		return c.Text(200, files[0].Filename)
+	})
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "big.txt")
+	_, _ = fw.Write(bytes.Repeat([]byte("x"), 1<<16))
+	_ = mw.Close()
+	resp, err := http.Post(srv.URL+"/upload", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != 200 || string(b) != "big.txt" {
+		t.Fatalf("upload: code %d body %q", resp.StatusCode, b)
+	}
+	left, _ := ioutil.ReadDir(dir)
+	if len(left) != 0 {
+		t.Errorf("multipart temp files left: %d", len(left))
+	}
+}
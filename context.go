@@ -4,12 +4,15 @@ package smile
 
 import (
 	"compress/gzip"
+	"context"
 	"errors"
 	"io/ioutil"
 	"mime/multipart"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 var (
@@ -86,20 +89,24 @@ type Context struct {
 	ResponseWriter
 	Request *http.Request
 	errs []error
-}
-
-//默认文件上传大小限制
+	params Params
+	routeGroup *RouteGroup
+	engine *Engine
+	bodyOptions BodyOptions //本次请求生效的请求体解析配置
+	formParsed bool //是否已解析表单
+	formErr error //解析表单的错误
+	mu sync.RWMutex //保护keys
+	keys map[string]interface{} //本次请求范围内的键值 参见 Set
+}
+
+//MaxFileSize 解析multipart表单时默认使用的内存 超出部分存储在临时文件中
+//可以通过 BodyOptions.MaxMemory 修改
 const (
 	MaxFileSize = 5 << 20
 )
 
-//用户自定上传大小限制
-var (
-	CustomFileSize int64
-)
-
 //initContext 初始化一个*Context
-//解析url传参 解析form-data
+//表单在首次获取参数时解析 参见 BodyOptions
 func initContext(w http.ResponseWriter, r *http.Request, e *Engine) *Context {
 
 	writer := &responseWriter{}
@@ -117,20 +124,10 @@ func initContext(w http.ResponseWriter, r *http.Request, e *Engine) *Context {
 		writer.Header().Set("Transfer-Encoding", "chunked")
 		writer.GzOn(gz)
 	}
-	var FileSize int64
-	if CustomFileSize > 0 {
-		FileSize = CustomFileSize
-	} else {
-		FileSize = MaxFileSize
-	}
-	c := &Context{ResponseWriter:writer, Request: r,handlerChain: newHandlerChain(),errs: make([]error,0)}
-	//解析传参数据
-	if err := r.ParseForm();err != nil {
-		c.errs = append(c.errs,err)
-	}
-	if err := r.ParseMultipartForm(FileSize);err != nil {
-		c.errs = append(c.errs,err)
-	}
+	c := &Context{ResponseWriter:writer, Request: r,handlerChain: newHandlerChain(),errs: make([]error,0),engine: e}
+	//请求的context在初始化时挂上键值 之后Set只修改键值 不再替换Request 后台goroutine可以安全读取
+	c.Request = r.WithContext(&valuesContext{Context: r.Context(), c: c})
+	c.bodyOptions = resolveBodyOptions(e, nil, nil)
 	return c
 }
 
@@ -218,18 +215,44 @@ func (c *Context) GetRawBody() string {
 	return string(b)
 }
 
+//Param 根据参数名获取路由中匹配到的路径参数
+//如路由 /users/:id 匹配 /users/10 时 Param("id") 返回 "10"
+func (c *Context) Param(key string) string {
+	return c.params.Get(key)
+}
+
+//Params 获取路由中匹配到的全部路径参数
+func (c *Context) Params() Params {
+	return c.params
+}
+
+//URLFor 根据路由名称及参数生成请求地址 参见 RouteGroup.URL
+func (c *Context) URLFor(name string, params ...string) (string, error) {
+	if c.routeGroup == nil {
+		return "", errors.New("context has no route group")
+	}
+	return c.routeGroup.URL(name, params...)
+}
+
 //GetQueryParam 根据键名从url参数中取值
+//表单已解析时同时从表单中取值
 func (c *Context) GetQueryParam(key string) string {
+	c.autoParseForm()
+	if c.Request.Form == nil {
+		return c.Request.URL.Query().Get(key)
+	}
 	return c.Request.Form.Get(key)
 }
 
 //GetPostParam 根据键名从post表单中取值
 func (c *Context) GetPostParam(key string) string {
-	return c.Request.PostFormValue(key)
+	c.autoParseForm()
+	return c.Request.PostForm.Get(key)
 }
 
 //GetMultipartFormParam 根据键名从form-data类型中取值
 func (c *Context) GetMultipartFormParam(key string) []string {
+	c.autoParseForm()
 	if c.Request.MultipartForm == nil {
 		return nil
 	}
@@ -238,6 +261,7 @@ func (c *Context) GetMultipartFormParam(key string) []string {
 
 //GetMultipartFormFile 根据键名冲form-data类型中取得上传文件头信息
 func (c *Context) GetMultipartFormFile(key string) []*multipart.FileHeader {
+	c.autoParseForm()
 	if c.Request.MultipartForm == nil {
 		return nil
 	}
@@ -246,6 +270,10 @@ func (c *Context) GetMultipartFormFile(key string) []*multipart.FileHeader {
 
 //GetFormFile 根据键名获取上传文件
 func (c *Context) GetFormFile(key string) (multipart.File, *multipart.FileHeader, error) {
+	c.autoParseForm()
+	if c.Request.MultipartForm == nil {
+		return nil, nil, http.ErrMissingFile
+	}
 	return c.Request.FormFile(key)
 }
 
@@ -266,6 +294,17 @@ func (c *Context) SetHeader(key, value string) {
 
 //Close 请求响应结束后的一些操作
 func (c *Context) Close() {
+	//表单解析在请求的副本上进行 net/http 只清理原请求的multipart临时文件
+	if c.Request.MultipartForm != nil {
+		_ = c.Request.MultipartForm.RemoveAll()
+	}
+	w := c.ResponseWriter.(*responseWriter)
+	//连接已被接管时 响应由接管方负责
+	if w.hijacked {
+		return
+	}
+	//只设置了响应状态而没有写入数据时(如304) 写入响应头
+	w.WriteHeaderAtOnce()
 	//如果本次请求使用gzip压缩 则关闭资源
 	if c.ResponseWriter.(*responseWriter).Gz() {
 		if c.ResponseWriter.(*responseWriter).Writer.(*gzip.Writer) != nil {
@@ -278,6 +317,12 @@ func (c *Context) Close() {
 func (c *Context) Redirect(url string) {
 	c.WriteHeader(http.StatusFound)
 	c.Header().Set("Location", url)
+	c.End()
+}
+
+//End 立即写入响应状态及响应头 以结束响应 用来执行跳转 或者单纯的header设置
+//原先的 c.Done() 已用于实现 context.Context 不再结束响应 请改用 End
+func (c *Context) End() {
 	c.ResponseWriter.Done()
 }
 
@@ -293,7 +338,34 @@ func (c *Context) Abort() bool {
 	c.handlerChain.abort()
 	return c.handlerChain.aborted
 }
-//Err 返回context中保存的错误
-func (c *Context) Err() []error {
+//Errors 返回本次请求中记录的错误 如解析表单的错误、请求体过大的错误
+func (c *Context) Errors() []error {
 	return c.errs
+}
+
+//Context 实现了 context.Context 可以直接传给需要context.Context的方法
+//Deadline Done Err Value 均委托给 Request.Context()
+//客户端断开或服务器关闭(Engine.Shutdown)时 Done 关闭
+var _ context.Context = &Context{}
+
+//Deadline 返回请求context的截止时间
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	return c.Request.Context().Deadline()
+}
+
+//Done 客户端断开或服务器关闭时关闭
+//注意 该方法不会结束响应 结束响应请使用 End
+func (c *Context) Done() <-chan struct{} {
+	return c.Request.Context().Done()
+}
+
+//Err Done关闭后返回取消的原因
+//本次请求中记录的错误请使用 Errors
+func (c *Context) Err() error {
+	return c.Request.Context().Err()
+}
+
+//Value 获取请求context中的值 包含Set保存的值
+func (c *Context) Value(key interface{}) interface{} {
+	return c.Request.Context().Value(key)
 }
\ No newline at end of file
@@ -2,11 +2,13 @@ package smile
 
 import (
 	"compress/gzip"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestContext(t *testing.T) {
@@ -71,7 +73,7 @@ func TestContext(t *testing.T) {
 
 	c.WriteString("testing string")
 	c.Flush()
-	c.Done()
+	c.End()
 
 	c.SetHeader("resp", "testSetHeader")
 	c.SetCookie(&http.Cookie{
@@ -114,3 +116,33 @@ func TestHandlerChain(t *testing.T) {
 		t.Error(err.Error())
 	}
 }
+
+func TestContextAsContext(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	r := httptest.NewRequest("GET", "/", nil).WithContext(parent)
+	c := initContext(httptest.NewRecorder(), r, Default())
+	c.Set("user", "tom")
+
+	var ctx context.Context = c
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("deadline should come from the request context")
+	}
+	if ctx.Value("user") != "tom" {
+		t.Errorf("value %v", ctx.Value("user"))
+	}
+	if ctx.Err() != nil {
+		t.Error("context should not be canceled yet")
+	}
+
+	//客户端断开时取消
+	cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done should close after the request context is canceled")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Errorf("err %v", ctx.Err())
+	}
+}
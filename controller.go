@@ -0,0 +1,225 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+//RouteSpec 控制器方法的路由声明
+type RouteSpec struct {
+	Action     string        //控制器方法名
+	Method     string        //请求方法 为空时使用FillRoutes传入的方法或方法名前缀对应的方法
+	Path       string        //相对于填充前缀的路径 可以包含路径参数 为空时按方法名称生成
+	Name       string        //路由名称 为空时不命名
+	Middleware []HandlerFunc //仅作用于该路由的中间件 在填充时传入的中间件之后执行
+}
+
+//RouteDescriber 控制器声明自身路由的接口
+//FillRoutes/PrefixFillRoutes 发现控制器实现该接口时 按声明注册对应的方法
+//同一个方法可以声明多条路由 未声明的方法仍按方法名称规则注册
+type RouteDescriber interface {
+	Routes() []RouteSpec
+}
+
+//BeforeActioner 控制器在每个自动注册的方法执行前调用的钩子
+//返回错误或者调用了Context.Abort时 不再执行对应方法
+type BeforeActioner interface {
+	BeforeAction(*Context) error
+}
+
+//AfterActioner 控制器在每个自动注册的方法执行后调用的钩子
+//err为BeforeAction或方法返回的错误 返回值作为最终的执行结果
+type AfterActioner interface {
+	AfterAction(*Context, error) error
+}
+
+//ControllerPerRequestOn 开启后自动注册的控制器在每次请求时复制出一个新的实例再调用方法
+//控制器可以在字段中安全保存本次请求的状态 需要在FillRoutes/PrefixFillRoutes之前调用
+func (rg *RouteGroup) ControllerPerRequestOn() {
+	rg.controllerPerRequest = true
+}
+
+//ControllerPerRequestOff 关闭后所有请求共用注册时传入的控制器实例
+func (rg *RouteGroup) ControllerPerRequestOff() {
+	rg.controllerPerRequest = false
+}
+
+//conventionFunc 根据方法名称生成请求方法及路径 无法生成时返回false
+type conventionFunc func(fnName string) (method string, path string, ok bool)
+
+//fillController 将控制器的方法注册到路由组中
+//声明中的方法不存在或者不是func(*Context) error时 将会panic
+func (rg *RouteGroup) fillController(prefix string, c interface{}, middleware []HandlerFunc, convention conventionFunc) {
+	t := reflect.TypeOf(c)
+	v := reflect.ValueOf(c)
+
+	specs := make(map[string][]RouteSpec)
+	if d, ok := c.(RouteDescriber); ok {
+		for _, spec := range d.Routes() {
+			if _, ok := t.MethodByName(spec.Action); !ok {
+				panic("controller " + t.String() + ": route action " + spec.Action + " not found")
+			}
+			specs[spec.Action] = append(specs[spec.Action], spec)
+		}
+	}
+
+	_, hasBefore := c.(BeforeActioner)
+	_, hasAfter := c.(AfterActioner)
+	l := t.NumMethod()
+	for i := 0; i < l; i++ {
+		fnName := t.Method(i).Name
+		list, described := specs[fnName]
+		if (hasBefore && fnName == "BeforeAction") || (hasAfter && fnName == "AfterAction") {
+			continue
+		}
+		//只检查命名规则或RouteSpec选中的方法 其他导出方法(如辅助方法)不做处理
+		method, path, ok := convention(fnName)
+		if !described && !ok {
+			continue
+		}
+		isAction, err := checkActionSignature(v.Method(i).Type())
+		if err != nil {
+			panic("controller " + t.String() + ": route action " + fnName + ": " + err.Error())
+		}
+		if !isAction {
+			if described {
+				panic("controller " + t.String() + ": route action " + fnName + " must take *Context as the first argument")
+			}
+			continue
+		}
+		fn := rg.controllerAction(v, i, hasBefore || hasAfter)
+		handlerName := t.String() + "." + fnName
+		if !described {
+			rg.Set(method, joinPath(prefix, path), withMiddleware(fn, middleware)...).autoFill(handlerName)
+			continue
+		}
+		for _, spec := range list {
+			specMethod, specPath := spec.Method, spec.Path
+			if specMethod == "" {
+				if !ok {
+					panic("controller " + t.String() + ": route action " + fnName + " has no method")
+				}
+				specMethod = method
+			}
+			if specPath == "" {
+				specPath = path
+			}
+			r := rg.Set(specMethod, joinPath(prefix, specPath), withMiddleware(fn, middleware, spec.Middleware)...)
+			r.autoFill(handlerName)
+			if spec.Name != "" {
+				r.Name(spec.Name)
+			}
+		}
+	}
+}
+
+var (
+	contextType = reflect.TypeOf((*Context)(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+//checkActionSignature 检查控制器方法的签名
+//第一个参数不是*Context的方法不作为请求处理方法 返回false
+//支持的签名为
+//	func(*Context) error
+//	func(*Context) (T, error)
+//	func(*Context, *Req) error
+//	func(*Context, *Req) (T, error)
+//其中Req为结构体 从请求中绑定 T为返回给客户端的数据
+func checkActionSignature(ft reflect.Type) (bool, error) {
+	if ft.NumIn() == 0 || ft.In(0) != contextType {
+		return false, nil
+	}
+	if ft.IsVariadic() || ft.NumIn() > 2 {
+		return true, errors.New("unsupported signature " + ft.String() + ", want at most (*Context, *Req)")
+	}
+	if ft.NumIn() == 2 && (ft.In(1).Kind() != reflect.Ptr || ft.In(1).Elem().Kind() != reflect.Struct) {
+		return true, errors.New("unsupported signature " + ft.String() + ", request argument must be a pointer to struct")
+	}
+	if ft.NumOut() == 0 || ft.NumOut() > 2 || ft.Out(ft.NumOut()-1) != errorType {
+		return true, errors.New("unsupported signature " + ft.String() + ", want error or (T, error) results")
+	}
+	return true, nil
+}
+
+//invokeAction 调用控制器方法
+//需要时从请求中绑定参数 方法返回的数据根据请求头Accept以JSON或XML格式输出
+func invokeAction(c *Context, m reflect.Value) error {
+	if fn, ok := m.Interface().(func(*Context) error); ok {
+		return fn(c)
+	}
+	ft := m.Type()
+	args := []reflect.Value{reflect.ValueOf(c)}
+	if ft.NumIn() == 2 {
+		req := reflect.New(ft.In(1).Elem())
+		if err := bindRequest(c, req.Interface()); err != nil {
+			c.WriteHeader(http.StatusBadRequest)
+			return err
+		}
+		args = append(args, req)
+	}
+	out := m.Call(args)
+	if err, _ := out[len(out)-1].Interface().(error); err != nil {
+		return err
+	}
+	if len(out) == 2 {
+		return renderResult(c, out[0])
+	}
+	return nil
+}
+
+//renderResult 输出控制器方法返回的数据 根据请求头Accept选择JSON或XML 数据为nil时响应204
+func renderResult(c *Context, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			c.NoContent(http.StatusNoContent)
+			return nil
+		}
+	}
+	return c.Negotiate(c.Status(), Offer{JSON: v.Interface(), XML: v.Interface()})
+}
+
+//controllerAction 生成调用控制器方法的HandlerFunc
+//没有钩子且不需要每次请求新建实例时 直接使用绑定的方法
+func (rg *RouteGroup) controllerAction(v reflect.Value, index int, hooks bool) HandlerFunc {
+	perRequest := rg.controllerPerRequest && v.Kind() == reflect.Ptr
+	if !hooks && !perRequest {
+		m := v.Method(index)
+		if fn, ok := m.Interface().(func(*Context) error); ok {
+			return fn
+		}
+		return func(c *Context) error {
+			return invokeAction(c, m)
+		}
+	}
+	return func(c *Context) (err error) {
+		recv := v
+		if perRequest {
+			//复制注册时的实例 保留其中注入的依赖
+			recv = reflect.New(v.Elem().Type())
+			recv.Elem().Set(v.Elem())
+		}
+		ctrl := recv.Interface()
+		if b, ok := ctrl.(BeforeActioner); ok {
+			err = b.BeforeAction(c)
+		}
+		if err == nil && !c.handlerChain.isAborted() {
+			err = invokeAction(c, recv.Method(index))
+		}
+		if a, ok := ctrl.(AfterActioner); ok {
+			err = a.AfterAction(c, err)
+		}
+		return err
+	}
+}
+
+//joinPath 拼接填充前缀与路径
+func joinPath(prefix, path string) string {
+	return strings.Trim(prefix+"/"+strings.Trim(path, "/"), "/")
+}
@@ -0,0 +1,231 @@
+package smile
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testSpecController struct{}
+
+func (t *testSpecController) Routes() []RouteSpec {
+	return []RouteSpec{
+		{Action: "Avatar", Method: MethodGet, Path: "/users/:id/avatar", Name: "avatar", Middleware: []HandlerFunc{routeMW}},
+		{Action: "Avatar", Method: MethodHead, Path: "/users/:id/avatar"},
+		{Action: "GetProfile", Method: MethodPost},
+		{Action: "GetDetail", Path: "/detail/:id"},
+	}
+}
+
+func (t *testSpecController) Avatar(c *Context) error     { return nil }
+func (t *testSpecController) GetProfile(c *Context) error { return nil }
+func (t *testSpecController) GetDetail(c *Context) error  { return nil }
+func (t *testSpecController) GetList(c *Context) error    { return nil }
+
+type testBadSpecController struct{}
+
+func (t *testBadSpecController) Routes() []RouteSpec {
+	return []RouteSpec{{Action: "Helper", Path: "/helper"}}
+}
+
+func (t *testBadSpecController) Helper() string { return "" }
+
+func TestFillRoutesWithSpecs(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.PrefixFillRoutes("/api", &testSpecController{})
+
+	found := [][2]string{
+		{MethodGet, "/api/users/7/avatar"},
+		{MethodHead, "/api/users/7/avatar"},
+		{MethodPost, "/api/profile"},
+		{MethodGet, "/api/detail/7"},
+		{MethodGet, "/api/list"},
+	}
+	for _, f := range found {
+		if _, err := rg.Get(f[0], f[1]); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := rg.Get(MethodGet, "/api/profile"); err == nil {
+		t.Error("described action should not fall back to its naming convention")
+	}
+	if u, _ := rg.URL("avatar", "id", "7"); u != "/api/users/7/avatar" {
+		t.Errorf("avatar url %q", u)
+	}
+	for _, info := range rg.Routes() {
+		if info.Name == "avatar" && (len(info.Middleware) != 1 || !info.AutoFilled) {
+			t.Errorf("avatar route info %+v", info)
+		}
+	}
+
+	//声明中没有请求方法时 使用FillRoutes传入的方法
+	rg = NewRouteGroup()
+	rg.FillRoutes(MethodPut, "/api", &testSpecController{})
+	for _, path := range []string{"/api/detail/7", "/api/get-list"} {
+		if _, err := rg.Get(MethodPut, path); err != nil {
+			t.Error(err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("describing an action with an unsupported signature should panic")
+		}
+	}()
+	rg.FillRoutes(MethodGet, "/bad", &testBadSpecController{})
+}
+
+type testHookController struct {
+	prefix string
+	calls  []string
+	user   string
+}
+
+func (t *testHookController) BeforeAction(c *Context) error {
+	t.calls = append(t.calls, "before")
+	t.user = c.GetQueryParam("user")
+	if t.user == "" {
+		return errors.New("no user")
+	}
+	return nil
+}
+
+func (t *testHookController) AfterAction(c *Context, err error) error {
+	t.calls = append(t.calls, "after")
+	if err != nil {
+		c.WriteHeader(403)
+		c.WriteString(err.Error())
+	}
+	return nil
+}
+
+func (t *testHookController) GetMe(c *Context) error {
+	t.calls = append(t.calls, "action")
+	c.WriteString(t.prefix + t.user)
+	return nil
+}
+
+func TestControllerHooks(t *testing.T) {
+	serve := func(rg *RouteGroup, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", url, nil)
+		c := initContext(w, r, Default())
+		engine := createEngine(false).Init(c)
+		engine.Check(rg)
+		if err := engine.Handle(); err != nil {
+			t.Error(err)
+		}
+		return w
+	}
+
+	shared := &testHookController{prefix: "hi "}
+	rg := NewRouteGroup()
+	rg.PrefixFillRoutes("", shared)
+	if _, err := rg.Get(MethodGet, "/before-action"); err == nil {
+		t.Error("BeforeAction hook should not be registered as a route")
+	}
+	if w := serve(rg, "/me?user=tom"); w.Body.String() != "hi tom" {
+		t.Errorf("body %q", w.Body.String())
+	}
+	if w := serve(rg, "/me"); w.Code != 403 || w.Body.String() != "no user" {
+		t.Errorf("code %d body %q", w.Code, w.Body.String())
+	}
+	if got := strings.Join(shared.calls, ","); got != "before,action,after,before,after" {
+		t.Errorf("calls %q", got)
+	}
+
+	fresh := &testHookController{prefix: "hello "}
+	rg = NewRouteGroup()
+	rg.ControllerPerRequestOn()
+	rg.PrefixFillRoutes("", fresh)
+	if w := serve(rg, "/me?user=ann"); w.Body.String() != "hello ann" {
+		t.Errorf("body %q", w.Body.String())
+	}
+	if len(fresh.calls) != 0 || fresh.user != "" {
+		t.Errorf("registered instance was modified: %+v", fresh)
+	}
+}
+
+type testBindReq struct {
+	ID    int      `path:"id"`
+	Name  string   `json:"name" form:"name"`
+	Tags  []string `query:"tag"`
+	Limit *int     `query:"limit"`
+}
+
+type testBindResp struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Limit int      `json:"limit"`
+}
+
+type testInjectController struct{}
+
+func (t *testInjectController) Routes() []RouteSpec {
+	return []RouteSpec{{Action: "Update", Method: MethodPost, Path: "/items/:id"}}
+}
+
+func (t *testInjectController) Update(c *Context, req *testBindReq) (*testBindResp, error) {
+	resp := &testBindResp{ID: req.ID, Name: req.Name, Tags: req.Tags}
+	if req.Limit != nil {
+		resp.Limit = *req.Limit
+	}
+	return resp, nil
+}
+
+func (t *testInjectController) GetEmpty(c *Context) (*testBindResp, error) {
+	return nil, nil
+}
+
+type testBadSignatureController struct{}
+
+func (t *testBadSignatureController) GetPage(c *Context, page int) error { return nil }
+
+//没有请求方法前缀的辅助方法不会成为路由 不检查签名
+func (t *testInjectController) Helper(c *Context, s string) string { return s }
+
+func TestControllerInjection(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.PrefixFillRoutes("", &testInjectController{})
+	serve := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c := initContext(w, r, Default())
+		engine := createEngine(false).Init(c)
+		engine.Check(rg)
+		if err := engine.Handle(); err != nil {
+			c.WriteString(err.Error())
+		}
+		c.Close()
+		return w
+	}
+
+	r := httptest.NewRequest("POST", "/items/7?tag=a&tag=b&limit=5", strings.NewReader(`{"name":"pen"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := serve(r)
+	if want := `{"id":7,"name":"pen","tags":["a","b"],"limit":5}`; w.Body.String() != want {
+		t.Errorf("body %q, want %q", w.Body.String(), want)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("content type %q", ct)
+	}
+
+	r = httptest.NewRequest("POST", "/items/7?limit=x", nil)
+	if w = serve(r); w.Code != 400 {
+		t.Errorf("bad request code %d", w.Code)
+	}
+
+	r = httptest.NewRequest("GET", "/empty", nil)
+	if w = serve(r); w.Code != 204 || w.Body.Len() != 0 {
+		t.Errorf("empty result code %d body %q", w.Code, w.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("unsupported action signature should panic")
+		}
+	}()
+	NewRouteGroup().PrefixFillRoutes("", &testBadSignatureController{})
+}
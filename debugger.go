@@ -4,7 +4,9 @@
 package smile
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"runtime/debug"
 )
 
@@ -26,14 +28,34 @@ func doDebug(e error, c *Context) {
 }
 
 //默认debug函数
+//校验未通过的错误 ValidationErrors 以422响应返回各字段的错误
+//请求体过大的错误 *BodyTooLargeError 以413响应
+//请求数据绑定失败的错误 *BindError 以400响应输出绑定错误
+//其他错误以JSON输出 响应状态未设置为错误状态时使用500
 func defaultDebugger(cb *Context, e error) {
+	var verrs ValidationErrors
+	if errors.As(e, &verrs) {
+		_ = renderError(cb, http.StatusUnprocessableEntity, http.StatusText(http.StatusUnprocessableEntity), verrs)
+		return
+	}
+	var tooLarge *BodyTooLargeError
+	if errors.As(e, &tooLarge) {
+		_ = renderError(cb, http.StatusRequestEntityTooLarge, tooLarge.Error(), nil)
+		return
+	}
+	var bindErr *BindError
+	if errors.As(e, &bindErr) {
+		_ = renderError(cb, http.StatusBadRequest, bindErr.Error(), nil)
+		return
+	}
 	stack := debug.Stack()
 	fmt.Printf("[debug_log] error: %s\n", e.Error())
 	fmt.Printf("[debug_log] stacks: %s\n", string(stack))
-	cb.Header().Add("Content-Type", "text/html;charset=utf-8;")
-	cb.WriteHeader(cb.Status())
-	str := fmt.Sprintf("{\"path\":\"%s\",\"status\":\"500\",\"message\":\"Internal Server Error \r\n %s\"}", cb.Request.URL.Path,e.Error())
-	cb.WriteString(str)
+	status := cb.Status()
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+	}
+	_ = renderError(cb, status, "Internal Server Error: "+e.Error(), nil)
 }
 
 //SetDebugger 由外部注入一个处理error的函数 会替换默认函数
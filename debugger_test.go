@@ -11,7 +11,6 @@ func debugFunc(c *Context) error {
 }
 
 func TestDoDebugger(t *testing.T) {
-	rg.SetGET("/test_debug", debugFunc)
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("", "/test_debug", nil)
 	c := initContext(w, r, Default())
@@ -28,3 +27,14 @@ func TestDoDebugger(t *testing.T) {
 	}
 
 }
+
+func TestValidationDebugger(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/orders", nil)
+	c := initContext(w, r, Default())
+	doDebug(ValidationErrors{{Field: "name", Rule: "required", Message: "is required"}}, c)
+	want := `{"path":"/orders","status":"422","message":"Unprocessable Entity","errors":[{"field":"name","rule":"required","message":"is required"}]}`
+	if w.Code != 422 || w.Body.String() != want {
+		t.Errorf("code %d body %s", w.Code, w.Body.String())
+	}
+}
@@ -4,7 +4,6 @@
 package smile
 
 import (
-	"net/http"
 	"os"
 	"path"
 	"strings"
@@ -105,7 +104,12 @@ func (e *ctxEngine) Check(i interface{}) bool {
 			fn = e.serveFile
 		}
 	}
+	var routeMiddleware []HandlerFunc
+	var route *Route
+	var group *RouteGroup
 	if rtg != nil {
+		e.cb.routeGroup = rtg
+		group = rtg
 		if fn == nil  {
 			method := ""
 			if e.cb.Request.Header.Get("Upgrade") == "websocket" {
@@ -113,27 +117,43 @@ func (e *ctxEngine) Check(i interface{}) bool {
 			} else {
 				method = e.method
 			}
-			Handler, err := rtg.Get(method, e.path)
-			if err == nil {
-				fn = Handler
+			if route = rtg.match(method, e.path, &e.cb.params); route != nil {
+				fn = route.handler()
+				routeMiddleware = route.handlers[:len(route.handlers)-1]
+				group = route.group
+			} else {
+				group = rtg.groupFor(e.path)
+				if allow := rtg.allowed(method, e.path); allow != "" {
+					//路径在其他请求方法下已经注册 返回405或自动应答OPTIONS
+					e.cb.Header().Set("Allow", allow)
+					if method == MethodOptions && group.autoOptionsOn() {
+						fn = defaultOptions
+					} else {
+						fn = group.handler405()
+					}
+				}
 			}
 		}
 		if fn == nil {
-			fn = rtg.route404
-		}
-		//加载所有路由
-		for _,f := range rtg.routeMiddleware {
-			e.cb.handlerChain.add(f)
+			fn = group.handler404()
 		}
+		//加载路由所在路由组及其上级路由组的中间件
+		group.addMiddleware(e.cb.handlerChain)
+	}
+	//按路由及路由组设置请求体解析配置 请求体超过大小限制时使用413回调
+	if e.cb.setBodyOptions(resolveBodyOptions(e.cb.engine, route, group)) != nil {
+		fn, routeMiddleware = group.handler413(), nil
+	}
+	//加载仅作用于该路由的中间件
+	for _, f := range routeMiddleware {
+		e.cb.handlerChain.add(f)
 	}
-	e.cb.handlerChain.add(fn)
+	e.cb.handlerChain.add(bodyLimitHandler(fn, group))
 	return true
 }
 
 func (e *ctxEngine) serveFile(c *Context) error {
-	//调用http包输出文件的方法
-	http.ServeFile(c.ResponseWriter, c.Request, e.path)
-	return nil
+	return c.File(e.path)
 }
 
 //Handle 执行已经保存的业务方法
@@ -12,6 +12,7 @@ func init() {
 	rg = NewRouteGroup()
 	rg.SetGET("test", testHandle)
 	rg.SetWS("test2", testHandle)
+	rg.SetGET("/test_debug", debugFunc)
 }
 
 func testHandle(c *Context) error {
@@ -83,3 +84,70 @@ func TestWsEngine(t *testing.T) {
 		t.Log("checkERR:", engine.Check(rg))
 	}
 }
+
+func TestMethodNotAllowed(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.SetGET("/users/:id", testHandle)
+	rg.SetPUT("/users/:id", testHandle)
+	api := rg.Group("/api")
+	api.SetGET("/items", testHandle)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		c := initContext(w, r, Default())
+		engine := createEngine(false).Init(c)
+		engine.Check(rg)
+		if err := engine.Handle(); err != nil {
+			t.Error(err)
+		}
+		return w
+	}
+
+	w := serve("POST", "/users/1")
+	if w.Code != 405 || w.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("POST: code=%d allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+	w = serve("POST", "/books/1")
+	if w.Code != 404 || w.Header().Get("Allow") != "" {
+		t.Errorf("POST unknown path: code=%d allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+	w = serve("OPTIONS", "/users/1")
+	if w.Code != 405 {
+		t.Errorf("OPTIONS without auto options: code=%d", w.Code)
+	}
+
+	//下级路由组单独开启自动应答
+	api.AutoOptionsOn()
+	w = serve("OPTIONS", "/api/items")
+	if w.Code != 204 || w.Header().Get("Allow") != "GET, OPTIONS" {
+		t.Errorf("OPTIONS on child group: code=%d allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+	w = serve("OPTIONS", "/users/1")
+	if w.Code != 405 || w.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("OPTIONS outside child group: code=%d allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+	api.AutoOptionsOff()
+
+	rg.AutoOptionsOn()
+	w = serve("OPTIONS", "/users/1")
+	if w.Code != 204 || w.Header().Get("Allow") != "GET, OPTIONS, PUT" {
+		t.Errorf("OPTIONS: code=%d allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+
+	rg.SetRoute405(func(c *Context) error {
+		c.WriteHeader(405)
+		c.WriteString("custom " + c.Header().Get("Allow"))
+		return nil
+	})
+	w = serve("DELETE", "/users/1")
+	if w.Body.String() != "custom GET, OPTIONS, PUT" {
+		t.Errorf("custom 405 body=%q", w.Body.String())
+	}
+
+	//下级路由组的设置优先于上级
+	w = serve("OPTIONS", "/api/items")
+	if w.Code != 405 || w.Header().Get("Allow") != "GET" {
+		t.Errorf("OPTIONS on child group turned off: code=%d allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+}
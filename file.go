@@ -0,0 +1,93 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+//File 输出文件 文件不存在或为目录时返回错误
+//支持Range/If-Range分段下载及If-Modified-Since等条件请求 本次响应不使用gzip压缩
+func (c *Context) File(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return errors.New("file " + path + " is a directory")
+	}
+	c.ServeContent(info.Name(), info.ModTime(), f)
+	return nil
+}
+
+//Attachment 以附件的方式输出文件 浏览器将以downloadName保存
+//downloadName按RFC 6266编码 非ASCII字符通过 filename* 传递
+func (c *Context) Attachment(path, downloadName string) error {
+	c.SetHeader("Content-Disposition", contentDisposition("attachment", downloadName))
+	return c.File(path)
+}
+
+//ServeContent 输出content中的内容 name用于推断Content-Type modtime用于条件请求
+//支持Range/If-Range分段下载及If-Match、If-Modified-Since等条件请求 本次响应不使用gzip压缩
+func (c *Context) ServeContent(name string, modtime time.Time, content io.ReadSeeker) {
+	c.gzOff()
+	http.ServeContent(c.ResponseWriter, c.Request, name, modtime, content)
+}
+
+//gzOff 关闭本次响应的gzip压缩
+func (c *Context) gzOff() {
+	if w, ok := c.ResponseWriter.(*responseWriter); ok {
+		w.GzOff()
+	}
+}
+
+//contentDisposition 生成Content-Disposition响应头
+//filename为只含ASCII字符的替代名称 原名称包含其他字符时再以 filename*=UTF-8'' 给出
+func contentDisposition(disposition, name string) string {
+	var fallback strings.Builder
+	ascii := true
+	for _, r := range name {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			ascii = false
+		case r > 0x7e || r == '"' || r == '\\' || r == '%':
+			ascii = false
+			fallback.WriteByte('_')
+		default:
+			fallback.WriteRune(r)
+		}
+	}
+	v := disposition + `; filename="` + fallback.String() + `"`
+	if !ascii {
+		v += "; filename*=UTF-8''" + encodeRFC5987(name)
+	}
+	return v
+}
+
+//encodeRFC5987 按RFC 5987对参数值进行百分号编码
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || strings.IndexByte("!#$&+-.^_`|~", ch) >= 0 {
+			b.WriteByte(ch)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[ch>>4])
+		b.WriteByte(hex[ch&15])
+	}
+	return b.String()
+}
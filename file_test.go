@@ -0,0 +1,106 @@
+package smile
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	serve := func(header http.Header, fn func(c *Context) error) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/download", nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		r.Header.Set("Accept-Encoding", "gzip")
+		c := initContext(w, r, Default())
+		if err := fn(c); err != nil {
+			t.Fatal(err)
+		}
+		c.Close()
+		return w
+	}
+	file := func(c *Context) error { return c.File(path) }
+
+	w := serve(nil, file)
+	if w.Code != 200 || w.Body.String() != "0123456789" || w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("file: code %d body %q encoding %q", w.Code, w.Body.String(), w.Header().Get("Content-Encoding"))
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("content type %q", ct)
+	}
+
+	w = serve(http.Header{"Range": {"bytes=2-4"}}, file)
+	if w.Code != 206 || w.Body.String() != "234" || w.Header().Get("Content-Range") != "bytes 2-4/10" {
+		t.Errorf("range: code %d body %q", w.Code, w.Body.String())
+	}
+
+	modified := w.Header().Get("Last-Modified")
+	w = serve(http.Header{"If-Modified-Since": {modified}}, file)
+	if w.Code != 304 || w.Body.Len() != 0 {
+		t.Errorf("not modified: code %d body %q", w.Code, w.Body.String())
+	}
+
+	w = serve(http.Header{"Range": {"bytes=0-1"}, "If-Range": {"Mon, 02 Jan 2006 15:04:05 GMT"}}, file)
+	if w.Code != 200 || w.Body.Len() != 10 {
+		t.Errorf("stale If-Range should send the whole file: code %d", w.Code)
+	}
+
+	w = serve(nil, func(c *Context) error { return c.Attachment(path, "报告 2024.txt") })
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="__ 2024.txt"; filename*=UTF-8''%E6%8A%A5%E5%91%8A%202024.txt` {
+		t.Errorf("attachment header %q", cd)
+	}
+	w = serve(nil, func(c *Context) error { return c.Attachment(path, "report.txt") })
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="report.txt"` {
+		t.Errorf("attachment header %q", cd)
+	}
+
+	w = serve(nil, func(c *Context) error {
+		c.ServeContent("data.json", time.Time{}, strings.NewReader("{}"))
+		return nil
+	})
+	if w.Body.String() != "{}" || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("serve content: %q %q", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+
+	c := initContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), Default())
+	if err := c.File(dir); err == nil {
+		t.Error("serving a directory should fail")
+	}
+	if err := c.File(filepath.Join(dir, "missing")); err == nil {
+		t.Error("serving a missing file should fail")
+	}
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (h hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestCloseAfterHijack(t *testing.T) {
+	w := hijackRecorder{httptest.NewRecorder()}
+	c := initContext(w, httptest.NewRequest("GET", "/", nil), Default())
+	c.WriteHeader(101)
+	if _, _, err := c.Hijack(); err != nil {
+		t.Fatal(err)
+	}
+	c.Close()
+	if w.Code == 101 {
+		t.Error("hijacked connection should not receive a response header")
+	}
+}
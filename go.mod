@@ -1,5 +1,5 @@
 module github.com/kasiss-liu/smile
 
-go 1.11
+go 1.16
 
 require github.com/gorilla/websocket v1.4.0
@@ -0,0 +1,127 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"strings"
+)
+
+//Group 生成一个下级路由组
+//下级路由组中注册的路由会加上路由组前缀 并依次经过上级路由组及本组的中间件
+//下级路由组可以继续嵌套 路由树与根路由组共享
+func (rg *RouteGroup) Group(prefix string, middleware ...HandlerFunc) *RouteGroup {
+	g := &RouteGroup{
+		trees:           rg.trees,
+		pathStyle:       rg.pathStyle,
+		routeMiddleware: append(make([]HandlerFunc, 0, len(middleware)), middleware...),
+		prefix:          rg.fullPath(prefix),
+		parent:          rg,
+		root:            rg.root,
+
+		controllerPerRequest: rg.controllerPerRequest,
+	}
+	if g.prefix == "/" {
+		g.prefix = ""
+	}
+	rg.root.groups = append(rg.root.groups, g)
+	return g
+}
+
+//Prefix 返回路由组的完整路径前缀
+func (rg *RouteGroup) Prefix() string {
+	return rg.prefix
+}
+
+//fullPath 为路由加上路由组前缀
+func (rg *RouteGroup) fullPath(path string) string {
+	return trimPath(rg.prefix + "/" + strings.Trim(path, "/"))
+}
+
+//groupFor 返回前缀与请求路径匹配最长的路由组
+//用于决定404/405时使用的回调方法和中间件
+func (rg *RouteGroup) groupFor(path string) *RouteGroup {
+	group := rg
+	for _, g := range rg.groups {
+		if len(g.prefix) <= len(group.prefix) {
+			continue
+		}
+		if path == g.prefix || strings.HasPrefix(path, g.prefix+"/") {
+			group = g
+		}
+	}
+	return group
+}
+
+//handler404 返回路由组生效的404回调 未设置时使用上级路由组的回调
+func (rg *RouteGroup) handler404() HandlerFunc {
+	for g := rg; g != nil; g = g.parent {
+		if g.route404 != nil {
+			return g.route404
+		}
+	}
+	return nil
+}
+
+//handler405 返回路由组生效的405回调 未设置时使用上级路由组的回调
+func (rg *RouteGroup) handler405() HandlerFunc {
+	for g := rg; g != nil; g = g.parent {
+		if g.route405 != nil {
+			return g.route405
+		}
+	}
+	return nil
+}
+
+//autoOptionsOn 返回路由组是否自动应答OPTIONS请求 未设置时使用上级路由组的配置
+func (rg *RouteGroup) autoOptionsOn() bool {
+	for g := rg; g != nil; g = g.parent {
+		if g.autoOptions != nil {
+			return *g.autoOptions
+		}
+	}
+	return false
+}
+
+//handler413 返回路由组生效的413回调 未设置时使用上级路由组的回调
+//没有路由组时使用默认回调
+func (rg *RouteGroup) handler413() HandlerFunc {
+	for g := rg; g != nil; g = g.parent {
+		if g.route413 != nil {
+			return g.route413
+		}
+	}
+	return defaultRoute413()
+}
+
+//contains 判断路由组是否是本组或本组的下级路由组
+func (rg *RouteGroup) contains(g *RouteGroup) bool {
+	for ; g != nil; g = g.parent {
+		if g == rg {
+			return true
+		}
+	}
+	return false
+}
+
+//middlewareNames 上级路由组到本组的中间件名称
+func (rg *RouteGroup) middlewareNames() []string {
+	var names []string
+	if rg.parent != nil {
+		names = rg.parent.middlewareNames()
+	}
+	for _, f := range rg.routeMiddleware {
+		names = append(names, getFuncName(f))
+	}
+	return names
+}
+
+//addMiddleware 将上级路由组到本组的中间件依次加入调用链
+func (rg *RouteGroup) addMiddleware(hc *handlerChain) {
+	if rg.parent != nil {
+		rg.parent.addMiddleware(hc)
+	}
+	for _, f := range rg.routeMiddleware {
+		hc.add(f)
+	}
+}
@@ -0,0 +1,122 @@
+package smile
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouteGroupNesting(t *testing.T) {
+	var trace []string
+	mark := func(name string) HandlerFunc {
+		return func(c *Context) error {
+			trace = append(trace, name)
+			return nil
+		}
+	}
+	handler := func(c *Context) error {
+		trace = append(trace, "handler")
+		return nil
+	}
+
+	rg := NewRouteGroup()
+	rg.SetMiddleware(mark("root"))
+	api := rg.Group("/api", mark("api"))
+	admin := api.Group("admin/", mark("admin"))
+	public := api.Group("/public")
+	admin.SetGET("/stats", handler)
+	public.SetGET("/news/:id", handler)
+	rg.SetGET("/health", handler)
+	admin.SetRoute404(func(c *Context) error {
+		trace = append(trace, "admin404")
+		return nil
+	})
+
+	if admin.Prefix() != "/api/admin" {
+		t.Errorf("admin prefix %q", admin.Prefix())
+	}
+
+	cases := []struct {
+		path  string
+		trace string
+	}{
+		{"/api/admin/stats", "root,api,admin,handler"},
+		{"/api/public/news/1", "root,api,handler"},
+		{"/health", "root,handler"},
+		{"/api/admin/missing", "root,api,admin,admin404"},
+	}
+	for _, cs := range cases {
+		trace = nil
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", cs.path, nil)
+		c := initContext(w, r, Default())
+		engine := createEngine(false).Init(c)
+		engine.Check(rg)
+		if err := engine.Handle(); err != nil {
+			t.Error(err)
+		}
+		if got := strings.Join(trace, ","); got != cs.trace {
+			t.Errorf("%s: trace %q, want %q", cs.path, got, cs.trace)
+		}
+	}
+
+	//中间件在路由注册之后添加同样生效
+	public.SetMiddleware(mark("public"))
+	trace = nil
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/api/public/news/2", nil)
+	c := initContext(w, r, Default())
+	engine := createEngine(false).Init(c)
+	engine.Check(rg)
+	engine.Handle()
+	if got := strings.Join(trace, ","); got != "root,api,public,handler" {
+		t.Errorf("trace %q", got)
+	}
+	if c.Param("id") != "2" {
+		t.Errorf("param id %q", c.Param("id"))
+	}
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	var trace []string
+	mark := func(name string) HandlerFunc {
+		return func(c *Context) error {
+			trace = append(trace, name)
+			return nil
+		}
+	}
+	denied := func(c *Context) error {
+		trace = append(trace, "denied")
+		c.Abort()
+		return nil
+	}
+
+	rg := NewRouteGroup()
+	admin := rg.Group("/admin", mark("group"))
+	admin.SetGET("/stats", mark("requireAdmin"), mark("audit"), mark("stats"))
+	admin.SetGET("/secret", denied, mark("secret"))
+	admin.FillRoutes(MethodGet, "/ctl", tfc, mark("fill"))
+
+	cases := []struct {
+		path  string
+		trace string
+	}{
+		{"/admin/stats", "group,requireAdmin,audit,stats"},
+		{"/admin/secret", "group,denied"},
+		{"/admin/ctl/func", "group,fill"},
+	}
+	for _, cs := range cases {
+		trace = nil
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", cs.path, nil)
+		c := initContext(w, r, Default())
+		engine := createEngine(false).Init(c)
+		engine.Check(rg)
+		if err := engine.Handle(); err != nil {
+			t.Error(err)
+		}
+		if got := strings.Join(trace, ","); got != cs.trace {
+			t.Errorf("%s: trace %q, want %q", cs.path, got, cs.trace)
+		}
+	}
+}
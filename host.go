@@ -0,0 +1,95 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"net"
+	"strings"
+)
+
+//hostRoute 一个按host分发的路由组
+//host支持三种写法 精确匹配 api.example.com
+//通配子域名 *.example.com 以及捕获子域名参数 :tenant.example.com
+type hostRoute struct {
+	pattern string
+	labels  []string //按 . 切分后的host片段 通配或参数片段只匹配一段
+	group   *RouteGroup
+}
+
+//match 匹配请求host 成功时将捕获到的子域名参数追加到ps中
+func (h *hostRoute) match(host string, ps *Params) bool {
+	mark := len(*ps)
+	for i, label := range h.labels {
+		idx := strings.IndexByte(host, '.')
+		if (idx < 0) != (i == len(h.labels)-1) {
+			*ps = (*ps)[:mark]
+			return false
+		}
+		part := host
+		if idx >= 0 {
+			part, host = host[:idx], host[idx+1:]
+		}
+		switch {
+		case label == "*":
+			if part == "" {
+				*ps = (*ps)[:mark]
+				return false
+			}
+		case label[0] == paramPrefix:
+			if part == "" {
+				*ps = (*ps)[:mark]
+				return false
+			}
+			*ps = append(*ps, Param{Key: label[1:], Value: part})
+		case label != part:
+			*ps = (*ps)[:mark]
+			return false
+		}
+	}
+	return true
+}
+
+//SetHostRouteGroup 注册一个按host分发的路由组
+//精确匹配的host优先 其余按注册顺序匹配 均未匹配时使用默认路由组 RouteGroup
+//捕获的子域名参数可以通过 Context.Param 获取 host不区分大小写 参数名保持原样
+func (e *Engine) SetHostRouteGroup(host string, rg *RouteGroup) {
+	if strings.IndexByte(host, '*') < 0 && strings.IndexByte(host, paramPrefix) < 0 {
+		if e.hostGroups == nil {
+			e.hostGroups = make(map[string]*RouteGroup, 5)
+		}
+		e.hostGroups[strings.ToLower(host)] = rg
+		return
+	}
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if label == "" || label == string(paramPrefix) ||
+			(strings.IndexByte(label, '*') >= 0 && label != "*") {
+			panic("host " + host + ": invalid host pattern")
+		}
+		if label[0] != paramPrefix {
+			labels[i] = strings.ToLower(label)
+		}
+	}
+	e.hostRoutes = append(e.hostRoutes, &hostRoute{pattern: host, labels: labels, group: rg})
+}
+
+//routeGroupFor 根据请求host选择路由组 捕获的子域名参数追加到ps中
+func (e *Engine) routeGroupFor(host string, ps *Params) *RouteGroup {
+	if len(e.hostGroups) == 0 && len(e.hostRoutes) == 0 {
+		return e.RouteGroup
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if rg, ok := e.hostGroups[host]; ok {
+		return rg
+	}
+	for _, h := range e.hostRoutes {
+		if h.match(host, ps) {
+			return h.group
+		}
+	}
+	return e.RouteGroup
+}
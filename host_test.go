@@ -0,0 +1,55 @@
+package smile
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHostRouting(t *testing.T) {
+	LogOFF()
+	defer LogON()
+	hostHandler := func(name string) HandlerFunc {
+		return func(c *Context) error {
+			c.WriteString(name + ":" + c.Param("tenantID") + ":" + c.Param("id"))
+			return nil
+		}
+	}
+	def := NewRouteGroup()
+	def.SetGET("/items/:id", hostHandler("default"))
+	api := NewRouteGroup()
+	api.SetGET("/items/:id", hostHandler("api"))
+	tenant := NewRouteGroup()
+	tenant.SetGET("/items/:id", hostHandler("tenant"))
+	wild := NewRouteGroup()
+	wild.SetGET("/items/:id", hostHandler("wild"))
+
+	e := Default()
+	e.GzipOff()
+	e.SetRouteGroup(def)
+	e.SetHostRouteGroup("api.example.com", api)
+	e.SetHostRouteGroup(":tenantID.Example.com", tenant)
+	e.SetHostRouteGroup("*.example.org", wild)
+
+	cases := []struct {
+		host string
+		body string
+	}{
+		{"api.example.com", "api::1"},
+		{"API.example.com:8080", "api::1"},
+		{"acme.example.com", "tenant:acme:1"},
+		{"Acme.EXAMPLE.com", "tenant:acme:1"},
+		{"a.b.example.com", "default::1"},
+		{"shop.example.org", "wild::1"},
+		{"example.org", "default::1"},
+		{"localhost", "default::1"},
+	}
+	for _, cs := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/items/1", nil)
+		r.Host = cs.host
+		e.ServeHTTP(w, r)
+		if w.Body.String() != cs.body {
+			t.Errorf("%s: body %q, want %q", cs.host, w.Body.String(), cs.body)
+		}
+	}
+}
@@ -139,6 +139,8 @@ func colorForMethod(method string) string {
 		return blue
 	case MethodDelete:
 		return red
+	case MethodPatch:
+		return green
 	default:
 		return white
 	}
@@ -0,0 +1,143 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+//Offer 内容协商时服务端可以提供的数据
+//未设置的字段不参与协商 同等条件下按 JSON XML HTML Text 的顺序选择
+type Offer struct {
+	JSON interface{} //以JSON编码输出
+	XML  interface{} //以XML编码输出
+	HTML string      //直接输出的HTML
+	Text string      //直接输出的纯文本
+}
+
+//offerTypes 每种数据可以匹配的媒体类型 第一个为响应的类型
+var offerTypes = [...][]string{
+	{"application/json"},
+	{"application/xml", "text/xml"},
+	{"text/html"},
+	{"text/plain"},
+}
+
+//Negotiate 根据请求头Accept从offer中选择一种格式 以status状态输出
+//Accept支持q值及 type/* */* 通配 q值相同时优先匹配更具体的类型 其次按服务端顺序
+//未设置Accept时使用第一种提供的格式 没有可接受的格式时响应406
+func (c *Context) Negotiate(status int, offer Offer) error {
+	i := negotiate(c.Request.Header.Get("Accept"), offer.offered())
+	if i < 0 {
+		return c.Text(http.StatusNotAcceptable, "not acceptable")
+	}
+	return c.renderOffer(status, offer, i)
+}
+
+//offered 返回每种数据是否提供 顺序与offerTypes一致
+func (o *Offer) offered() []bool {
+	return []bool{o.JSON != nil, o.XML != nil, o.HTML != "", o.Text != ""}
+}
+
+//renderOffer 以offerTypes中下标为i的格式输出
+func (c *Context) renderOffer(status int, offer Offer, i int) error {
+	switch i {
+	case 0:
+		return c.JSON(status, offer.JSON)
+	case 1:
+		return c.XML(status, offer.XML)
+	case 2:
+		return c.HTMLString(status, offer.HTML)
+	}
+	return c.Text(status, offer.Text)
+}
+
+//mediaRange Accept中的一项
+type mediaRange struct {
+	typ, sub string
+	q        float64
+}
+
+//parseAccept 解析Accept请求头 q值无效的项按1处理
+func parseAccept(accept string) []mediaRange {
+	parts := strings.Split(accept, ",")
+	ranges := make([]mediaRange, 0, len(parts))
+	for _, part := range parts {
+		params := strings.Split(part, ";")
+		typ := strings.ToLower(strings.TrimSpace(params[0]))
+		slash := strings.IndexByte(typ, '/')
+		if slash <= 0 || slash == len(typ)-1 {
+			continue
+		}
+		r := mediaRange{typ: typ[:slash], sub: typ[slash+1:], q: 1}
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if len(p) > 2 && (p[0] == 'q' || p[0] == 'Q') && p[1] == '=' {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil && q >= 0 && q <= 1 {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+//specificity 媒体类型与Accept中一项的匹配程度 0为不匹配
+func (r mediaRange) specificity(mediaType string) int {
+	slash := strings.IndexByte(mediaType, '/')
+	switch {
+	case r.typ == "*" && r.sub == "*":
+		return 1
+	case r.typ != mediaType[:slash]:
+		return 0
+	case r.sub == "*":
+		return 2
+	case r.sub == mediaType[slash+1:]:
+		return 3
+	}
+	return 0
+}
+
+//negotiate 返回选中的offerTypes下标 没有可接受的格式时返回-1
+func negotiate(accept string, offered []bool) int {
+	best, bestQ, bestSpec := -1, 0.0, 0
+	if strings.TrimSpace(accept) == "" {
+		for i, ok := range offered {
+			if ok {
+				return i
+			}
+		}
+		return -1
+	}
+	ranges := parseAccept(accept)
+	for i, ok := range offered {
+		if !ok {
+			continue
+		}
+		//每种数据取最具体的匹配项的q值
+		q, spec := 0.0, 0
+		for j, mediaType := range offerTypes[i] {
+			for _, r := range ranges {
+				s := r.specificity(mediaType)
+				if j > 0 && s < 3 {
+					//别名只参与精确匹配 如 text/* 不会匹配到 text/xml
+					continue
+				}
+				if s > spec || (s == spec && s > 0 && r.q > q) {
+					q, spec = r.q, s
+				}
+			}
+		}
+		if spec == 0 || q == 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && spec > bestSpec) {
+			best, bestQ, bestSpec = i, q, spec
+		}
+	}
+	return best
+}
@@ -0,0 +1,76 @@
+package smile
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	all := []bool{true, true, true, true}
+	tests := []struct {
+		accept  string
+		offered []bool
+		want    int
+	}{
+		{"", all, 0},
+		{"", []bool{false, false, true, true}, 2},
+		{"*/*", all, 0},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", all, 2},
+		{"application/xml, application/json", all, 0},
+		{"text/xml", all, 1},
+		{"text/*", all, 2},
+		{"text/*, text/plain", all, 3},
+		{"application/json;q=0.5, text/*;q=0.5", all, 0},
+		{"text/*;q=0.5, application/json;q=0.4", all, 2},
+		{"application/json;q=0, */*", all, 1},
+		{"TEXT/PLAIN", all, 3},
+		{"image/png", all, -1},
+		{"application/json", []bool{false, false, true, true}, -1},
+		{"garbage, text/plain", all, 3},
+	}
+	for _, tt := range tests {
+		if got := negotiate(tt.accept, tt.offered); got != tt.want {
+			t.Errorf("negotiate(%q) = %d, want %d", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestContextNegotiate(t *testing.T) {
+	offer := Offer{JSON: map[string]int{"n": 1}, HTML: "<b>1</b>", Text: "1"}
+	serve := func(accept string, offer Offer) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", accept)
+		c := initContext(w, r, Default())
+		if err := c.Negotiate(200, offer); err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+	if w := serve("text/html", offer); w.Body.String() != "<b>1</b>" || w.Header().Get("Content-Type") != contentTypeHTML {
+		t.Errorf("html: %q %q", w.Body.String(), w.Header().Get("Content-Type"))
+	}
+	if w := serve("application/*", offer); w.Body.String() != `{"n":1}` {
+		t.Errorf("json: %q", w.Body.String())
+	}
+	if w := serve("application/xml", offer); w.Code != 406 {
+		t.Errorf("xml not offered: code %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/missing", nil)
+	r.Header.Set("Accept", "text/html,*/*;q=0.8")
+	_ = defaultRoute404()(initContext(w, r, Default()))
+	if w.Code != 404 || !strings.Contains(w.Body.String(), "<h1>404 Not Found</h1>") {
+		t.Errorf("404 for browsers: %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/missing", nil)
+	r.Header.Set("Accept", "image/png")
+	_ = defaultRoute404()(initContext(w, r, Default()))
+	if w.Code != 404 || w.Header().Get("Content-Type") != contentTypeJSON {
+		t.Errorf("404 without acceptable type: %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
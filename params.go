@@ -0,0 +1,91 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+//Param 一个路径参数
+type Param struct {
+	Key   string
+	Value string
+}
+
+//Params 路径参数列表 按照在路由中出现的顺序排列
+type Params []Param
+
+//Get 根据参数名获取参数值 不存在时返回空字符串
+func (ps Params) Get(key string) string {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+//paramTypes 路由参数可以使用的类型约束 如 /orders/{id:int}
+var paramTypes = map[string]func(string) bool{
+	"int":  isIntParam,
+	"uuid": isUUIDParam,
+	"date": isDateParam,
+}
+
+//SetParamType 注册一个路由参数类型约束 会替换同名的约束
+//需要在注册使用该约束的路由之前调用
+func SetParamType(name string, fn func(string) bool) {
+	paramTypes[name] = fn
+}
+
+//newParamCheck 根据约束表达式生成参数校验函数
+//表达式为已注册的类型名时使用类型约束 否则作为正则表达式完整匹配参数值
+func newParamCheck(expr string) (func(string) bool, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if fn, ok := paramTypes[expr]; ok {
+		return fn, nil
+	}
+	reg, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return reg.MatchString, nil
+}
+
+//isIntParam 十进制整数 允许负号
+func isIntParam(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+//isUUIDParam 8-4-4-4-12格式的uuid 不区分大小写
+func isUUIDParam(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+//isDateParam 2006-01-02格式的日期
+func isDateParam(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
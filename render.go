@@ -0,0 +1,107 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	contentTypeJSON = "application/json; charset=utf-8"
+	contentTypeXML  = "application/xml; charset=utf-8"
+	contentTypeText = "text/plain; charset=utf-8"
+	contentTypeHTML = "text/html; charset=utf-8"
+)
+
+//JSON 将v编码为JSON后以status状态输出
+//编码失败时不写入任何响应 返回错误
+func (c *Context) JSON(status int, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Data(status, contentTypeJSON, b)
+}
+
+//XML 将v编码为XML后以status状态输出
+//编码失败时不写入任何响应 返回错误
+func (c *Context) XML(status int, v interface{}) error {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Data(status, contentTypeXML, b)
+}
+
+//Text 以status状态输出纯文本
+func (c *Context) Text(status int, s string) error {
+	return c.Data(status, contentTypeText, []byte(s))
+}
+
+//HTMLString 以status状态输出一段HTML
+func (c *Context) HTMLString(status int, html string) error {
+	return c.Data(status, contentTypeHTML, []byte(html))
+}
+
+//Data 以status状态及指定的Content-Type输出原始数据
+func (c *Context) Data(status int, contentType string, data []byte) error {
+	c.Header().Set("Content-Type", contentType)
+	c.WriteHeader(status)
+	_, err := c.Write(data)
+	return err
+}
+
+//NoContent 只输出status状态 不输出响应体 如 204
+func (c *Context) NoContent(status int) {
+	c.WriteHeader(status)
+	c.End()
+}
+
+//errorBody 默认的404/405/422/500等错误响应的JSON格式
+type errorBody struct {
+	Path    string           `json:"path"`
+	Status  string           `json:"status"`
+	Message string           `json:"message"`
+	Errors  ValidationErrors `json:"errors,omitempty"`
+}
+
+//renderError 输出默认的错误响应 根据请求头Accept选择JSON、HTML或纯文本 参见 Negotiate
+func renderError(c *Context, status int, message string, verrs ValidationErrors) error {
+	title := strconv.Itoa(status) + " " + http.StatusText(status)
+	text := title + "\n" + message
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html><html><head><title>" + title + "</title></head><body><h1>" + title + "</h1><p>")
+	b.WriteString(html.EscapeString(message))
+	b.WriteString("</p>")
+	if len(verrs) > 0 {
+		b.WriteString("<ul>")
+		for _, e := range verrs {
+			b.WriteString("<li>" + html.EscapeString(e.Error()) + "</li>")
+			text += "\n" + e.Error()
+		}
+		b.WriteString("</ul>")
+	}
+	b.WriteString("</body></html>")
+	offer := Offer{
+		JSON: errorBody{
+			Path:    c.Request.URL.Path,
+			Status:  strconv.Itoa(status),
+			Message: message,
+			Errors:  verrs,
+		},
+		HTML: b.String(),
+		Text: text,
+	}
+	//没有可接受的格式时仍输出JSON 不以406覆盖原本的错误状态
+	i := negotiate(c.Request.Header.Get("Accept"), offer.offered())
+	if i < 0 {
+		i = 0
+	}
+	return c.renderOffer(status, offer, i)
+}
@@ -0,0 +1,79 @@
+package smile
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+	tests := []struct {
+		render func(*Context) error
+		code   int
+		ct     string
+		body   string
+	}{
+		{func(c *Context) error { return c.JSON(201, user{`a"b`}) }, 201, contentTypeJSON, `{"name":"a\"b"}`},
+		{func(c *Context) error { return c.XML(200, user{"a<b"}) }, 200, contentTypeXML, `<user><name>a&lt;b</name></user>`},
+		{func(c *Context) error { return c.Text(400, "bad") }, 400, contentTypeText, "bad"},
+		{func(c *Context) error { return c.HTMLString(200, "<p>hi</p>") }, 200, contentTypeHTML, "<p>hi</p>"},
+		{func(c *Context) error { return c.Data(200, "image/png", []byte("png")) }, 200, "image/png", "png"},
+		{func(c *Context) error { c.NoContent(204); return nil }, 204, "", ""},
+	}
+	for i, tt := range tests {
+		w := httptest.NewRecorder()
+		c := initContext(w, httptest.NewRequest("GET", "/", nil), Default())
+		if err := tt.render(c); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != tt.code || w.Header().Get("Content-Type") != tt.ct || w.Body.String() != tt.body {
+			t.Errorf("%d: code %d content type %q body %q", i, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c := initContext(w, httptest.NewRequest("GET", "/", nil), Default())
+	if err := c.JSON(200, func() {}); err == nil || w.Body.Len() != 0 {
+		t.Errorf("unencodable value: err %v body %q", err, w.Body.String())
+	}
+}
+
+func TestRenderErrorBodies(t *testing.T) {
+	var body errorBody
+
+	w := httptest.NewRecorder()
+	c := initContext(w, httptest.NewRequest("GET", `/a"b`, nil), Default())
+	_ = defaultRoute404()(c)
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != 404 || body.Path != `/a"b` || body.Status != "404" {
+		t.Errorf("404: %v code %d body %s", err, w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != contentTypeJSON {
+		t.Errorf("404 content type %q", ct)
+	}
+
+	w = httptest.NewRecorder()
+	c = initContext(w, httptest.NewRequest("GET", "/", nil), Default())
+	doDebug(errors.New(`broken "quote"`), c)
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != 500 || body.Message != `Internal Server Error: broken "quote"` {
+		t.Errorf("500: %v code %d body %s", err, w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c = initContext(w, httptest.NewRequest("GET", "/", nil), Default())
+	doDebug(&BindError{Source: "path", Field: "id", Value: "x", Err: errors.New("invalid syntax")}, c)
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != 400 || body.Message != `bind path id: invalid value "x": invalid syntax` {
+		t.Errorf("400: %v code %d body %s", err, w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c = initContext(w, httptest.NewRequest("GET", "/", nil), Default())
+	c.WriteHeader(403)
+	doDebug(errors.New("denied"), c)
+	if w.Code != 403 {
+		t.Errorf("debugger should keep error status, got %d", w.Code)
+	}
+}
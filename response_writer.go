@@ -36,6 +36,7 @@ type responseWriter struct {
 	status  int  //响应状态
 	size    int  //响应字节长度
 	written bool
+	hijacked bool //连接已被接管 如websocket 不能再写入响应
 }
 
 //初始化http.ResponseWriter 响应状态 响应数据长度
@@ -44,6 +45,7 @@ func (w *responseWriter) Init(writer http.ResponseWriter) {
 	w.status = defaultStatus
 	w.ResponseWriter = writer
 	w.written = false
+	w.hijacked = false
 }
 
 //开启gz开关
@@ -54,6 +56,18 @@ func (w *responseWriter) GzOn(gz *gzip.Writer) {
 	w.gz = true
 }
 
+//关闭gz开关 本次请求的响应不再压缩
+//同时移除开启gz时设置的响应头 响应头已写入时不做处理
+func (w *responseWriter) GzOff() {
+	if !w.gz || w.isWritten() {
+		return
+	}
+	w.gz = false
+	w.Writer = nil
+	w.Header().Del("Content-Encoding")
+	w.Header().Del("Transfer-Encoding")
+}
+
 //获取响应数据字节长度
 func (w *responseWriter) DataSize() int {
 	return w.size
@@ -121,14 +135,27 @@ func (w *responseWriter) WriteString(data string) (n int, err error) {
 }
 
 //继承http.Hijacker的Hijack()方法
+//接管成功后记录状态 请求结束时不再写入响应头
 func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return w.ResponseWriter.(http.Hijacker).Hijack()
-
+	conn, rw, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
 }
 
 //继承http.flusher的Flush()方法
+//先写入响应头 开启gz时将*gzip.Writer中缓存的数据压缩写出 再刷新底层writer
 func (w *responseWriter) Flush() {
-	w.ResponseWriter.(http.Flusher).Flush()
+	w.WriteHeaderAtOnce()
+	if w.gz {
+		if gz, ok := w.Writer.(*gzip.Writer); ok {
+			_ = gz.Flush()
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
 }
 
 //继承http.CloseNotifier的CloseNotify()方法
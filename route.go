@@ -0,0 +1,195 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Route 一条注册在路由树上的路由
+type Route struct {
+	method      string
+	pattern     string
+	name        string
+	handlers    []HandlerFunc //路由中间件及业务方法 业务方法位于最后
+	handlerName string        //业务方法名称 自动注册时为 控制器类型.方法名
+	autoFilled  bool          //是否由FillRoutes/PrefixFillRoutes自动注册
+	group       *RouteGroup   //注册路由的路由组 决定生效的中间件
+	bodyOptions *BodyOptions  //路由的请求体解析配置 未设置时使用路由组的配置
+}
+
+//RouteInfo 路由信息 用于查看已注册的路由表
+type RouteInfo struct {
+	Method     string   //请求方法
+	Pattern    string   //包含路由组前缀的完整路径
+	Name       string   //路由名称 未命名时为空
+	Handler    string   //业务方法名称
+	Middleware []string //依次执行的中间件名称 包含路由组中间件及路由中间件
+	AutoFilled bool     //是否自动注册
+}
+
+//handler 返回路由的业务方法
+func (r *Route) handler() HandlerFunc {
+	return r.handlers[len(r.handlers)-1]
+}
+
+//autoFill 标记路由为自动注册 并记录控制器方法名称
+func (r *Route) autoFill(handlerName string) *Route {
+	r.autoFilled = true
+	r.handlerName = handlerName
+	return r
+}
+
+//Info 返回路由信息
+func (r *Route) Info() RouteInfo {
+	middleware := r.group.middlewareNames()
+	for _, f := range r.handlers[:len(r.handlers)-1] {
+		middleware = append(middleware, getFuncName(f))
+	}
+	return RouteInfo{
+		Method:     r.method,
+		Pattern:    r.pattern,
+		Name:       r.name,
+		Handler:    r.handlerName,
+		Middleware: middleware,
+		AutoFilled: r.autoFilled,
+	}
+}
+
+//Routes 返回本路由组及下级路由组中注册的全部路由信息 按路径及请求方法排序
+func (rg *RouteGroup) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(rg.root.routes))
+	for _, r := range rg.root.routes {
+		if rg.contains(r.group) {
+			routes = append(routes, r.Info())
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+//format 将路由信息格式化为一行字符串 width为路径的对齐宽度
+func (info RouteInfo) format(width int) string {
+	s := fmt.Sprintf("%-7s %-"+strconv.Itoa(width)+"s --> %s", info.Method, info.Pattern, info.Handler)
+	if len(info.Middleware) > 0 {
+		s += " (" + strings.Join(info.Middleware, ", ") + ")"
+	}
+	if info.Name != "" {
+		s += " name:" + info.Name
+	}
+	return s
+}
+
+//routesPathWidth 路由表打印时路径的对齐宽度
+func routesPathWidth(routes []RouteInfo) int {
+	width := 10
+	for _, info := range routes {
+		if len(info.Pattern) > width {
+			width = len(info.Pattern)
+		}
+	}
+	return width
+}
+
+//Method 返回路由的请求方法
+func (r *Route) Method() string {
+	return r.method
+}
+
+//Pattern 返回路由包含路由组前缀的完整路径
+func (r *Route) Pattern() string {
+	return r.pattern
+}
+
+//Name 为路由命名 命名后可以通过 RouteGroup.URL 或 Context.URLFor 反向生成地址
+//同一个名称只能对应一个路径 重复命名不同路径时将会panic
+func (r *Route) Name(name string) *Route {
+	root := r.group.root
+	if exist, ok := root.names[name]; ok && exist.pattern != r.pattern {
+		panic("route name " + name + " already used by " + exist.method + " " + exist.pattern)
+	}
+	if root.names == nil {
+		root.names = make(map[string]*Route, 10)
+	}
+	root.names[name] = r
+	r.name = name
+	return r
+}
+
+//URL 根据参数生成路由的请求地址
+//params为 键,值 成对出现的列表 键与路由参数同名时填充到路径中 其余的作为url参数
+func (r *Route) URL(params ...string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", errors.New("route " + r.pattern + ": params must be key/value pairs")
+	}
+	used := make([]bool, len(params)/2)
+	var b strings.Builder
+	path := r.pattern
+	for {
+		i := wildcardIndex(path)
+		if i < 0 {
+			b.WriteString(path)
+			break
+		}
+		b.WriteString(path[:i])
+		path = path[i:]
+		key, _, end, catchAll, _ := parseWildcard(path)
+		value, ok := "", false
+		for j := 0; j < len(params); j += 2 {
+			if params[j] == key {
+				value, ok = params[j+1], true
+				used[j/2] = true
+				break
+			}
+		}
+		if !ok {
+			return "", errors.New("route " + r.pattern + ": missing parameter " + key)
+		}
+		if catchAll {
+			//通配参数保留路径中的 / 逐段转义
+			segments := strings.Split(value, "/")
+			for k, seg := range segments {
+				segments[k] = url.PathEscape(seg)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			if value == "" {
+				return "", errors.New("route " + r.pattern + ": empty parameter " + key)
+			}
+			b.WriteString(url.PathEscape(value))
+		}
+		path = path[end:]
+	}
+	query := url.Values{}
+	for j := 0; j < len(params); j += 2 {
+		if !used[j/2] {
+			query.Add(params[j], params[j+1])
+		}
+	}
+	if len(query) > 0 {
+		b.WriteString("?")
+		b.WriteString(query.Encode())
+	}
+	return b.String(), nil
+}
+
+//URL 根据路由名称及参数生成请求地址
+//params为 键,值 成对出现的列表 如 URL("user", "id", "10", "tab", "books") -> /users/10?tab=books
+func (rg *RouteGroup) URL(name string, params ...string) (string, error) {
+	r, ok := rg.root.names[name]
+	if !ok {
+		return "", errors.New("route name " + name + " not found")
+	}
+	return r.URL(params...)
+}
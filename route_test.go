@@ -0,0 +1,116 @@
+package smile
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteURL(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.SetGET("/users/:id", testHandle).Name("user")
+	rg.Group("/files").SetGET("/*path", testHandle).Name("file")
+	rg.SetGET("/search", testHandle).Name("search")
+
+	cases := []struct {
+		name   string
+		params []string
+		url    string
+		err    bool
+	}{
+		{"user", []string{"id", "10"}, "/users/10", false},
+		{"user", []string{"id", "a b", "tab", "books"}, "/users/a%20b?tab=books", false},
+		{"file", []string{"path", "docs/a b.txt"}, "/files/docs/a%20b.txt", false},
+		{"search", []string{"q", "go", "page", "2"}, "/search?page=2&q=go", false},
+		{"user", nil, "", true},
+		{"user", []string{"id"}, "", true},
+		{"missing", nil, "", true},
+	}
+	for _, cs := range cases {
+		u, err := rg.URL(cs.name, cs.params...)
+		if (err != nil) != cs.err || u != cs.url {
+			t.Errorf("%s %v: url=%q err=%v", cs.name, cs.params, u, err)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("reusing a route name for another path should panic")
+		}
+	}()
+	rg.SetPOST("/users", testHandle).Name("user")
+}
+
+func TestContextURLFor(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.SetGET("/books/:id", func(c *Context) error {
+		u, err := c.URLFor("book", "id", c.Param("id"))
+		if err != nil {
+			return err
+		}
+		c.WriteString(u)
+		return nil
+	}).Name("book")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/books/7", nil)
+	c := initContext(w, r, Default())
+	engine := createEngine(false).Init(c)
+	engine.Check(rg)
+	if err := engine.Handle(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Body.String() != "/books/7" {
+		t.Errorf("body %q", w.Body.String())
+	}
+}
+
+func TestRouteURLWithConstraint(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.SetGET("/orders/{id:int}/items", testHandle).Name("order-items")
+	u, err := rg.URL("order-items", "id", "5")
+	if err != nil || u != "/orders/5/items" {
+		t.Errorf("url=%q err=%v", u, err)
+	}
+}
+
+func routeMW(c *Context) error { return nil }
+
+func TestRoutesInfo(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.SetMiddleware(routeMW)
+	rg.SetPOST("/users", testHandle)
+	rg.SetGET("/users", routeMW, testHandle).Name("users")
+	rg.Group("/ctl").PrefixFillRoutes("", tc)
+
+	routes := rg.Routes()
+	if len(routes) != 5 {
+		t.Fatalf("got %d routes: %#v", len(routes), routes)
+	}
+	want := []RouteInfo{
+		{Method: MethodPost, Pattern: "/ctl/func", Handler: "*smile.testController.PostFunc", AutoFilled: true},
+		{Method: MethodWs, Pattern: "/ctl/func", Handler: "*smile.testController.WsFunc", AutoFilled: true},
+		{Method: MethodGet, Pattern: "/ctl/func-test", Handler: "*smile.testController.GetFuncTest", AutoFilled: true},
+		{Method: MethodGet, Pattern: "/users", Name: "users"},
+		{Method: MethodPost, Pattern: "/users"},
+	}
+	for i, w := range want {
+		got := routes[i]
+		if got.Method != w.Method || got.Pattern != w.Pattern || got.Name != w.Name || got.AutoFilled != w.AutoFilled {
+			t.Errorf("route %d: got %+v, want %+v", i, got, w)
+		}
+		if w.Handler != "" && got.Handler != w.Handler {
+			t.Errorf("route %d: handler %q, want %q", i, got.Handler, w.Handler)
+		}
+	}
+	if mw := routes[3].Middleware; len(mw) != 2 || mw[0] != getFuncName(routeMW) {
+		t.Errorf("middleware %v", mw)
+	}
+	if len(rg.Group("/ctl").Routes()) != 0 {
+		t.Error("a new group should not list routes of its siblings")
+	}
+
+	assign, auto := rg.FormatRoutes()
+	if len(assign) != 2 || len(auto) != 3 {
+		t.Errorf("assign=%v auto=%v", assign, auto)
+	}
+}
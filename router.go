@@ -5,12 +5,11 @@ package smile
 
 import (
 	"errors"
-	"fmt"
-	"reflect"
+	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"sync"
 )
 
 //HandlerFunc 定一个业务执行方法
@@ -18,227 +17,250 @@ type HandlerFunc func(*Context) error
 
 //定义部分请求类型及其匹配式
 const (
-	MethodGet    = "GET"
-	MethodPost   = "POST"
-	MethodWs     = "WS"
-	MethodPut    = "PUT"
-	MethodDelete = "DELETE"
-	regexpPost   = "(POST)|(Post)|"
-	regexpGet    = "(GET)|(Get)|"
-	regexpWs     = "(WS)|(Ws)|"
-	regexpPut    = "(PUT)|(Put)|"
-	regexpDet    = "(DELETE)|(Delete)|"
+	MethodGet     = "GET"
+	MethodPost    = "POST"
+	MethodWs      = "WS"
+	MethodPut     = "PUT"
+	MethodDelete  = "DELETE"
+	MethodPatch   = "PATCH"
+	MethodHead    = "HEAD"
+	MethodOptions = "OPTIONS"
+	MethodConnect = "CONNECT"
+	MethodTrace   = "TRACE"
+	regexpPost    = "(POST)|(Post)|"
+	regexpGet     = "(GET)|(Get)|"
+	regexpWs      = "(WS)|(Ws)|"
+	regexpPut     = "(PUT)|(Put)|"
+	regexpDet     = "(DELETE)|(Delete)|"
+	regexpPatch   = "(PATCH)|(Patch)|"
+	regexpHead    = "(HEAD)|(Head)|"
+	regexpOpt     = "(OPTIONS)|(Options)|"
+	regexpCon     = "(CONNECT)|(Connect)|"
+	regexpTrace   = "(TRACE)|(Trace)|"
 )
 
+//anyMethods Any注册时使用的全部标准请求方法
+var anyMethods = []string{
+	MethodGet, MethodPost, MethodPut, MethodDelete, MethodPatch,
+	MethodHead, MethodOptions, MethodConnect, MethodTrace,
+}
+
+//isPrefixMethod 判断是否是前缀规则可以识别的请求方法
+func isPrefixMethod(method string) bool {
+	if method == MethodWs {
+		return true
+	}
+	for _, m := range anyMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+//isValidMethod 判断请求方法是否是合法的token 以便支持 PROPFIND 等扩展方法
+func isValidMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+	for i := 0; i < len(method); i++ {
+		if c := method[i]; c <= ' ' || c >= 0x7f || strings.IndexByte(`()<>@,;:\"/[]?={}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
 //定义自动生成路由的风格
 const (
 	StyleHump    = "hump"
 	StyleConnect = "connector"
 )
 
-//path的打印基准长度
-const pathLen = 10
-
-type fnameList map[string]string
-
 //RouteGroup 路由列表
+//每种请求方法对应一棵压缩前缀树
 type RouteGroup struct {
-	GET                      map[string]HandlerFunc
-	POST                     map[string]HandlerFunc
-	WS                       map[string]HandlerFunc
-	PUT                      map[string]HandlerFunc
-	DELETE                   map[string]HandlerFunc
-	pathStyle                string               //自动填充路由时 方法名称转化为路径后的风格
-	routeAssignFnameList     map[string]fnameList //手写路由->方法名列表
-	routeAutoCreateFnameList map[string]fnameList //自动注册路由->方法名列表
-	autoFilling              bool                 //是否在自动生成路由
-	autoFillLock             sync.Mutex
-	route404				 HandlerFunc
-	routeMiddleware 		 []HandlerFunc
-
-}
-
-//initRouteFnameList  初始化注册路由对应的执行方法名称
-func (rg *RouteGroup) initRouteFnameList() {
-	list := make(map[string]fnameList, 5)
-	list[MethodGet] = make(map[string]string, 10)
-	list[MethodPost] = make(map[string]string, 10)
-	list[MethodPut] = make(map[string]string, 10)
-	list[MethodWs] = make(map[string]string, 10)
-	list[MethodDelete] = make(map[string]string, 10)
-	rg.routeAssignFnameList = list
-
-	list = make(map[string]fnameList, 5)
-	list[MethodGet] = make(map[string]string, 10)
-	list[MethodPost] = make(map[string]string, 10)
-	list[MethodPut] = make(map[string]string, 10)
-	list[MethodWs] = make(map[string]string, 10)
-	list[MethodDelete] = make(map[string]string, 10)
-	rg.routeAutoCreateFnameList = list
-}
-
-//setRouteFnameList 记录注册路由对应的执行方法名称
-func (rg *RouteGroup) setRouteFnameList(method, path string, handler interface{}) {
-	switch v := handler.(type) {
-	case string:
-		rg.routeAutoCreateFnameList[method][path] = v
-	case HandlerFunc:
-		rg.routeAssignFnameList[method][path] = getFuncName(handler)
-	}
+	trees           map[string]*node //请求方法->路由树
+	pathStyle       string           //自动填充路由时 方法名称转化为路径后的风格
+	route404        HandlerFunc
+	route405        HandlerFunc
+	route413        HandlerFunc
+	autoOptions     *bool //是否根据已注册的请求方法自动应答OPTIONS请求 未设置时使用上级路由组的配置
+	routeMiddleware []HandlerFunc
+	prefix          string            //路由组的路径前缀 根路由组为空
+	parent          *RouteGroup       //上级路由组 根路由组为nil
+	root            *RouteGroup       //根路由组 路由树在同一根下共享
+	groups          []*RouteGroup     //根路由组记录的全部下级路由组
+	names           map[string]*Route //根路由组记录的命名路由
+	routes          []*Route          //根路由组记录的全部路由 按注册顺序排列
+
+	controllerPerRequest bool         //自动注册的控制器是否在每次请求时新建实例
+	bodyOptions          *BodyOptions //请求体解析配置 未设置时使用上级路由组的配置
 }
 
-//Set 注册一个路由
-func (rg *RouteGroup) Set(method string, path string, handler HandlerFunc) {
-	path = trimPath(path)
-	set := true
-	switch method {
-	case MethodGet:
-		rg.GET[path] = handler
-	case MethodPost:
-		rg.POST[path] = handler
-	case MethodWs:
-		rg.WS[path] = handler
-	case MethodPut:
-		rg.PUT[path] = handler
-	case MethodDelete:
-		rg.DELETE[path] = handler
-	default:
-		set = false
+//Set 注册一个路由 返回的*Route可用于为路由命名
+//method可以是任意合法的请求方法 包括 PROPFIND 等扩展方法
+//路由可以包含命名参数 :name 以及位于末尾的通配参数 *name
+//handlers中最后一个为业务方法 之前的为仅作用于该路由的中间件 在路由组中间件之后执行
+//与已注册路由产生歧义时(同一位置参数名不同、重复注册) 将会panic
+func (rg *RouteGroup) Set(method string, path string, handlers ...HandlerFunc) *Route {
+	path = rg.fullPath(path)
+	if !isValidMethod(method) {
+		panic("route " + path + ": invalid method " + strconv.Quote(method))
+	}
+	if len(handlers) == 0 {
+		panic("route " + method + " " + path + ": no handler")
+	}
+	root, ok := rg.trees[method]
+	if !ok {
+		root = &node{}
+		rg.trees[method] = root
 	}
-	if set && !rg.autoFilling {
-		rg.setRouteFnameList(method, path, handler)
+	r := &Route{
+		method:      method,
+		pattern:     path,
+		handlers:    append([]HandlerFunc(nil), handlers...),
+		handlerName: getFuncName(handlers[len(handlers)-1]),
+		group:       rg,
 	}
+	if err := root.addRoute(path, r); err != nil {
+		panic(method + " " + err.Error())
+	}
+	rg.root.routes = append(rg.root.routes, r)
+	return r
 }
 
 //SetGET 注册一个GET方法请求到的路由
-func (rg *RouteGroup) SetGET(path string, handler HandlerFunc) {
-	rg.Set(MethodGet, path, handler)
+func (rg *RouteGroup) SetGET(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodGet, path, handlers...)
 }
 
 //SetPOST 注册一个POST方法可用的路由
-func (rg *RouteGroup) SetPOST(path string, handler HandlerFunc) {
-	rg.Set(MethodPost, path, handler)
+func (rg *RouteGroup) SetPOST(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodPost, path, handlers...)
 }
 
 //SetWS 注册一个websocket路由
-func (rg *RouteGroup) SetWS(path string, handler HandlerFunc) {
-	rg.Set(MethodWs, path, handler)
+func (rg *RouteGroup) SetWS(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodWs, path, handlers...)
 }
 
 //SetPUT 注册一个PUT方法可用的路由
-func (rg *RouteGroup) SetPUT(path string, handler HandlerFunc) {
-	rg.Set(MethodPut, path, handler)
+func (rg *RouteGroup) SetPUT(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodPut, path, handlers...)
 }
 
 //SetDEL 注册一个PUT方法可用的路由
-func (rg *RouteGroup) SetDEL(path string, handler HandlerFunc) {
-	rg.Set(MethodDelete, path, handler)
+func (rg *RouteGroup) SetDEL(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodDelete, path, handlers...)
+}
+
+//SetPATCH 注册一个PATCH方法可用的路由
+func (rg *RouteGroup) SetPATCH(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodPatch, path, handlers...)
+}
+
+//SetHEAD 注册一个HEAD方法可用的路由
+func (rg *RouteGroup) SetHEAD(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodHead, path, handlers...)
+}
+
+//SetOPTIONS 注册一个OPTIONS方法可用的路由
+func (rg *RouteGroup) SetOPTIONS(path string, handlers ...HandlerFunc) *Route {
+	return rg.Set(MethodOptions, path, handlers...)
+}
+
+//Any 在全部标准请求方法上注册同一个路由 不包含websocket
+func (rg *RouteGroup) Any(path string, handlers ...HandlerFunc) {
+	for _, method := range anyMethods {
+		rg.Set(method, path, handlers...)
+	}
 }
 
 //Get 根据请求方法 获取一个注册的方法
 func (rg *RouteGroup) Get(method string, path string) (HandlerFunc, error) {
-	switch method {
-	case MethodGet:
-		if val, ok := rg.GET[path]; ok {
-			return val, nil
-		}
-	case MethodPost:
-		if val, ok := rg.POST[path]; ok {
-			return val, nil
-		}
-	case MethodWs:
-		if val, ok := rg.WS[path]; ok {
-			return val, nil
-		}
-	case MethodPut:
-		if val, ok := rg.PUT[path]; ok {
-			return val, nil
+	fn, _, err := rg.Lookup(method, path)
+	return fn, err
+}
+
+//Lookup 根据请求方法和路径 获取注册的方法及路径中匹配到的参数
+//同一位置的匹配优先级为 静态路径 > 命名参数 > 通配参数
+func (rg *RouteGroup) Lookup(method string, path string) (HandlerFunc, Params, error) {
+	var ps Params
+	if r := rg.match(method, path, &ps); r != nil {
+		return r.handler(), ps, nil
+	}
+	return nil, nil, errors.New("METHOD:" + method + " PATH:" + path + " DID NOT REGISTER YET")
+}
+
+//allowed 返回路径在其他请求方法下已注册的方法列表 用于Allow响应头
+//路径没有在任何方法下注册时返回空字符串
+func (rg *RouteGroup) allowed(method string, path string) string {
+	methods := make([]string, 0, len(rg.trees)+1)
+	hasOptions := false
+	var ps Params
+	for m, root := range rg.trees {
+		//websocket不是真正的请求方法 不出现在Allow中
+		if m == method || m == MethodWs {
+			continue
 		}
-	case MethodDelete:
-		if val, ok := rg.DELETE[path]; ok {
-			return val, nil
+		ps = ps[:0]
+		if root.getValue(path, &ps) != nil {
+			methods = append(methods, m)
+			hasOptions = hasOptions || m == MethodOptions
 		}
-	default:
 	}
-	return nil, errors.New("METHOD:" + method + " PATH:" + path + " DID NOT REGISTER YET")
+	if len(methods) == 0 {
+		return ""
+	}
+	if rg.groupFor(path).autoOptionsOn() && !hasOptions {
+		methods = append(methods, MethodOptions)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+//match 在请求方法对应的路由树中查找路由 参数追加到ps中
+func (rg *RouteGroup) match(method string, path string, ps *Params) *Route {
+	if root, ok := rg.trees[method]; ok {
+		return root.getValue(path, ps)
+	}
+	return nil
 }
 
 //NewRouteGroup 生成一个新的路由列表
 func NewRouteGroup() *RouteGroup {
 	r := &RouteGroup{}
-	r.GET = make(map[string]HandlerFunc, 10)
-	r.POST = make(map[string]HandlerFunc, 10)
-	r.WS = make(map[string]HandlerFunc, 10)
-	r.PUT = make(map[string]HandlerFunc, 10)
-	r.DELETE = make(map[string]HandlerFunc, 10)
+	r.trees = make(map[string]*node, 5)
 	r.SetPathStyleConnector()
-	r.initRouteFnameList()
 	r.route404 = defaultRoute404()
-	r.routeMiddleware = make([]HandlerFunc,0,5)
+	r.route405 = defaultRoute405()
+	r.route413 = defaultRoute413()
+	r.routeMiddleware = make([]HandlerFunc, 0, 5)
+	r.root = r
 	return r
 }
 
 //FillRoutes 填充路由基础方法
-func (rg *RouteGroup) FillRoutes(method string, prefix string, c interface{}) {
-	//自动生成路由时，开启路由自动注册锁
-	rg.autoFillLock.Lock()
-	rg.autoFilling = true
-	defer func() {
-		rg.autoFilling = false
-		rg.autoFillLock.Unlock()
-	}()
-
-	t := reflect.TypeOf(c)
-	v := reflect.ValueOf(c)
-	l := t.NumMethod()
-	for i := 0; i < l; i++ {
-		fnName := t.Method(i).Name
-		interf := v.Method(i).Interface()
-		set := true
-		if fn, ok := interf.(func(*Context) error); ok {
-			fnName = rg.transFnNameToPath(fnName)
-			path := strings.Trim(prefix+"/"+fnName, "/")
-			switch method {
-			case MethodGet:
-				rg.SetGET(path, fn)
-			case MethodPost:
-				rg.SetPOST(path, fn)
-			case MethodWs:
-				rg.SetWS(path, fn)
-			case MethodPut:
-				rg.SetPUT(path, fn)
-			case MethodDelete:
-				rg.SetDEL(path, fn)
-			default:
-				set = false
-			}
-			if set {
-				rg.setRouteFnameList(method, trimPath(path), t.String()+"."+t.Method(i).Name)
-			}
-		}
-	}
+//middleware作用于本次填充的每一个路由
+//控制器实现了RouteDescriber时 按声明注册对应方法 其余方法仍按方法名称注册
+func (rg *RouteGroup) FillRoutes(method string, prefix string, c interface{}, middleware ...HandlerFunc) {
+	rg.fillController(prefix, c, middleware, func(fnName string) (string, string, bool) {
+		return method, rg.transFnNameToPath(fnName), true
+	})
 }
 
 //PrefixFillRoutes 前缀匹配规则 填充路由
-//暂时只支持GET、POST、WS
+//支持 Get/Post/Ws/Put/Delete/Patch/Head/Options/Connect/Trace 前缀
+//前缀之后须为大写字母或方法名结束 如 HeaderInfo Tracer 不会被注册
 //将一个Controller结构下的方法按照方法名称注册到routeGroup中
-func (rg *RouteGroup) PrefixFillRoutes(prefix string, c interface{}) {
-	//自动生成路由时，开启路由自动注册锁
-	rg.autoFillLock.Lock()
-	rg.autoFilling = true
-	defer func() {
-		rg.autoFilling = false
-		rg.autoFillLock.Unlock()
-	}()
-
-	t := reflect.TypeOf(c)
-	v := reflect.ValueOf(c)
-	l := t.NumMethod()
-	reg, _ := regexp.Compile(`^(` + regexpPost + regexpGet + regexpWs + regexpPut + regexpDet + `).+`)
-	var method string
-	for i := 0; i < l; i++ {
-		fnName := t.Method(i).Name
-		interf := v.Method(i).Interface()
+//middleware作用于本次填充的每一个路由
+//控制器实现了RouteDescriber时 按声明注册对应方法 其余方法仍按前缀规则注册
+func (rg *RouteGroup) PrefixFillRoutes(prefix string, c interface{}, middleware ...HandlerFunc) {
+	reg, _ := regexp.Compile(`^(` + regexpPost + regexpGet + regexpWs + regexpPut + regexpDet +
+		regexpPatch + regexpHead + regexpOpt + regexpCon + regexpTrace + `)([A-Z].*)?$`)
+	rg.fillController(prefix, c, middleware, func(fnName string) (string, string, bool) {
+		var method string
 		rexSubmatch := reg.FindStringSubmatch(fnName)
 		if len(rexSubmatch) > 0 {
 			method = strings.ToUpper(rexSubmatch[1])
@@ -248,32 +270,27 @@ func (rg *RouteGroup) PrefixFillRoutes(prefix string, c interface{}) {
 				method = strings.ToUpper(rexSubmatch[0])
 			} else {
 				fnName = strings.Replace(fnName, rexSubmatch[1], "", -1)
+				if fnName == "" {
+					fnName = "/"
+				}
 			}
 		}
-		if fn, ok := interf.(func(*Context) error); ok {
-			//函数名称转化为请求路径path的全小写格式
-			fnName = rg.transFnNameToPath(fnName)
-			path := strings.Trim(prefix+"/"+fnName, "/")
-			set := true
-			switch method {
-			case MethodGet:
-				rg.SetGET(path, fn)
-			case MethodPost:
-				rg.SetPOST(path, fn)
-			case MethodWs:
-				rg.SetWS(path, fn)
-			case MethodPut:
-				rg.SetPUT(path, fn)
-			case MethodDelete:
-				rg.SetDEL(path, fn)
-			default:
-				set = false
-			}
-			if set {
-				rg.setRouteFnameList(method, trimPath(path), t.String()+"."+t.Method(i).Name)
-			}
+		//没有可识别前缀的方法不做注册
+		if !isPrefixMethod(method) {
+			return "", "", false
 		}
+		//函数名称转化为请求路径path的全小写格式
+		return method, rg.transFnNameToPath(fnName), true
+	})
+}
+
+//withMiddleware 生成 中间件+业务方法 的新调用列表
+func withMiddleware(fn HandlerFunc, middleware ...[]HandlerFunc) []HandlerFunc {
+	handlers := make([]HandlerFunc, 0, 5)
+	for _, list := range middleware {
+		handlers = append(handlers, list...)
 	}
+	return append(handlers, fn)
 }
 
 //SetPathStyleHump 设置路径风格为驼峰 即区分大小写
@@ -298,42 +315,23 @@ func (rg *RouteGroup) transFnNameToPath(fnName string) string {
 }
 
 //FormatRoutes 返回格式化的路由信息 每个路由信息为一个string
+//手写注册的路由与自动注册的路由分别返回 均按路径及请求方法排序
 func (rg *RouteGroup) FormatRoutes() (rsAssign []string, rsAuto []string) {
-	return rg.formatRoutes(rg.routeAssignFnameList),
-		rg.formatRoutes(rg.routeAutoCreateFnameList)
-}
-
-//formatRoutes 将路由信息格式化为字符串数组
-func (rg *RouteGroup) formatRoutes(rglist map[string]fnameList) []string {
-	rm := make(map[string]string, 10)
-	rt := make(map[string][]byte)
-	baseLen := pathLen
-	for method, rgroup := range rglist {
-		for path, fnName := range rgroup {
-			rt[path] = []byte(method)
-			rm[path] = fnName
+	routes := rg.Routes()
+	width := routesPathWidth(routes)
+	for _, info := range routes {
+		if info.AutoFilled {
+			rsAuto = append(rsAuto, info.format(width))
+		} else {
+			rsAssign = append(rsAssign, info.format(width))
 		}
 	}
-	//重组每条路由信息数据
-	rs := make([]string, 0, 30)
-	for path, fnName := range rm {
-		s := fmt.Sprintf("%-6s  %-"+strconv.Itoa(baseLen)+"s --> %s",
-			rt[path],
-			path,
-			fnName,
-		)
-		rs = append(rs, s)
-	}
-	return rs
+	return
 }
 
 func defaultRoute404() HandlerFunc {
 	return func(cb *Context) error {
-		cb.Header().Add("Content-Type", "text/html;charset=utf-8;")
-		cb.WriteHeader(404)
-		str := fmt.Sprintf("{\"path\":\"%s\",\"status\":\"404\",\"message\":\"not found\"}", cb.Request.URL.Path)
-		_, err := cb.WriteString(str)
-		return err
+		return renderError(cb, http.StatusNotFound, "not found", nil)
 	}
 }
 
@@ -342,7 +340,52 @@ func (rg *RouteGroup) SetRoute404(fn HandlerFunc) {
 	rg.route404 = fn
 }
 
+func defaultRoute405() HandlerFunc {
+	return func(cb *Context) error {
+		return renderError(cb, http.StatusMethodNotAllowed, "method not allowed", nil)
+	}
+}
+
+//SetRoute405 注册405回调方法
+//请求路径在其他请求方法下已注册时调用 调用前已设置好Allow响应头
+func (rg *RouteGroup) SetRoute405(fn HandlerFunc) {
+	rg.route405 = fn
+}
+
+func defaultRoute413() HandlerFunc {
+	return func(cb *Context) error {
+		return renderError(cb, http.StatusRequestEntityTooLarge, "payload too large", nil)
+	}
+}
+
+//SetRoute413 注册413回调方法
+//请求体超过 BodyOptions.MaxBodySize 时调用 请求头Content-Length已超过限制时不再执行路由的业务方法
+//读取请求体时才超过限制的 业务方法返回该错误或未写入响应时调用
+func (rg *RouteGroup) SetRoute413(fn HandlerFunc) {
+	rg.route413 = fn
+}
+
+//defaultOptions 自动应答OPTIONS请求 Allow响应头已在路由匹配时设置
+func defaultOptions(cb *Context) error {
+	cb.NoContent(http.StatusNoContent)
+	return nil
+}
+
+//AutoOptionsOn 开启OPTIONS请求自动应答 根据路径已注册的请求方法返回Allow
+//作用于路由组及未单独设置的下级路由组
+func (rg *RouteGroup) AutoOptionsOn() {
+	on := true
+	rg.autoOptions = &on
+}
+
+//AutoOptionsOff 关闭OPTIONS请求自动应答 未注册的OPTIONS请求返回405
+func (rg *RouteGroup) AutoOptionsOff() {
+	off := false
+	rg.autoOptions = &off
+}
+
 //SetMiddleware 注册中间件
+//中间件作用于本路由组及其下级路由组中的全部路由 与注册顺序无关
 func (rg *RouteGroup) SetMiddleware(fn HandlerFunc) {
-	rg.routeMiddleware = append(rg.routeMiddleware,fn)
+	rg.routeMiddleware = append(rg.routeMiddleware, fn)
 }
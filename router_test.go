@@ -2,6 +2,7 @@
 package smile
 
 import (
+	"net/http/httptest"
 	"testing"
 )
 
@@ -49,6 +50,14 @@ func (t *testController) WsFunc(c *Context) error {
 	return nil
 }
 
+//前缀后不是大写字母的方法不注册
+type testWordController struct{}
+
+func (t *testWordController) HeaderInfo(c *Context) error { return nil }
+func (t *testWordController) Tracer(c *Context) error     { return nil }
+func (t *testWordController) Connection(c *Context) error { return nil }
+func (t *testWordController) Get(c *Context) error        { return nil }
+
 var tc = &testController{}
 
 func TestFillPrefixRoutes(t *testing.T) {
@@ -84,6 +93,12 @@ func TestFillPrefixRoutes(t *testing.T) {
 		t.Log("success")
 	}
 
+	words := NewRouteGroup()
+	words.PrefixFillRoutes("/w", &testWordController{})
+	if routes := words.Routes(); len(routes) != 1 || routes[0].Method != MethodGet || routes[0].Pattern != "/w" {
+		t.Errorf("only Get should be registered: %+v", routes)
+	}
+
 	rg.SetPathStyleHump()
 	rg.PrefixFillRoutes("", tc)
 	fn, err = rg.Get(MethodGet, "/FuncTest")
@@ -102,7 +117,7 @@ func TestFormatRouteGroup(t *testing.T) {
 	rg.SetGET("/gettest", tc.GetFuncTest)
 	rsAssign, rsAuto := rg.FormatRoutes()
 	t.Logf("%#v\n%#v\n", rsAssign, rsAuto)
-	doPrintRoutes(rsAssign, rsAuto)
+	doPrintRoutes(rg.Routes())
 }
 
 func TestSetRouteFuncs(t *testing.T) {
@@ -116,3 +131,165 @@ func TestSetRouteFuncs(t *testing.T) {
 		return nil
 	})
 }
+
+func TestPathParams(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.SetGET("/users/:id", func(c *Context) error {
+		c.WriteString("user " + c.Param("id"))
+		return nil
+	})
+	rg.SetGET("/users/:id/books/:book", testHandle)
+	rg.SetGET("/assets/*filepath", testHandle)
+	rg.SetGET("/users/new", testHandle)
+
+	cases := []struct {
+		path   string
+		params Params
+		found  bool
+	}{
+		{"/users/10", Params{{"id", "10"}}, true},
+		{"/users/new", nil, true},
+		{"/users/10/books/go", Params{{"id", "10"}, {"book", "go"}}, true},
+		{"/assets/css/main.css", Params{{"filepath", "css/main.css"}}, true},
+		{"/assets", Params{{"filepath", ""}}, true},
+		{"/users", nil, false},
+		{"/users/10/books", nil, false},
+	}
+	for _, cs := range cases {
+		_, ps, err := rg.Lookup(MethodGet, cs.path)
+		if (err == nil) != cs.found {
+			t.Errorf("%s: found=%v, want %v", cs.path, err == nil, cs.found)
+			continue
+		}
+		if len(ps) != len(cs.params) {
+			t.Errorf("%s: params=%v, want %v", cs.path, ps, cs.params)
+			continue
+		}
+		for i := range ps {
+			if ps[i] != cs.params[i] {
+				t.Errorf("%s: params=%v, want %v", cs.path, ps, cs.params)
+			}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/users/42", nil)
+	c := initContext(w, r, Default())
+	engine := createEngine(false).Init(c)
+	engine.Check(rg)
+	if err := engine.Handle(); err != nil {
+		t.Error(err)
+	}
+	if c.Param("id") != "42" || w.Body.String() != "user 42" {
+		t.Errorf("param=%q body=%q", c.Param("id"), w.Body.String())
+	}
+}
+
+func TestSetConflictPanics(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.SetGET("/users/:id", testHandle)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("ambiguous route registered without panic")
+		}
+	}()
+	rg.SetGET("/users/:name", testHandle)
+}
+
+var benchPaths = []string{
+	"/", "/users", "/users/profile", "/users/settings", "/orders",
+	"/orders/history", "/products", "/products/search", "/cart", "/checkout",
+}
+
+func BenchmarkMapLookup(b *testing.B) {
+	m := make(map[string]HandlerFunc, len(benchPaths))
+	for _, p := range benchPaths {
+		m[p] = testHandle
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m[benchPaths[i%len(benchPaths)]]
+	}
+}
+
+func BenchmarkTreeLookupStatic(b *testing.B) {
+	rg := NewRouteGroup()
+	for _, p := range benchPaths {
+		rg.SetGET(p, testHandle)
+	}
+	var ps Params
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ps = ps[:0]
+		_ = rg.match(MethodGet, benchPaths[i%len(benchPaths)], &ps)
+	}
+}
+
+func BenchmarkTreeLookupParam(b *testing.B) {
+	rg := NewRouteGroup()
+	rg.SetGET("/users/:id/books/:book", testHandle)
+	ps := make(Params, 0, 2)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ps = ps[:0]
+		_ = rg.match(MethodGet, "/users/42/books/go", &ps)
+	}
+}
+
+type testVerbController struct{}
+
+func (t *testVerbController) PatchUser(c *Context) error   { return nil }
+func (t *testVerbController) HeadUser(c *Context) error    { return nil }
+func (t *testVerbController) OptionsUser(c *Context) error { return nil }
+func (t *testVerbController) Options(c *Context) error     { return nil }
+func (t *testVerbController) Index(c *Context) error       { return nil }
+
+func TestAllMethods(t *testing.T) {
+	rg := NewRouteGroup()
+	rg.Set("PROPFIND", "/dav/*path", testHandle)
+	rg.SetPATCH("/patch", testHandle)
+	rg.Any("/any", testHandle)
+	rg.FillRoutes("MKCOL", "/dav", tfc)
+	rg.PrefixFillRoutes("/verb", &testVerbController{})
+
+	found := [][2]string{
+		{"PROPFIND", "/dav/a/b"},
+		{MethodPatch, "/patch"},
+		{"MKCOL", "/dav/func"},
+		{MethodPatch, "/verb/user"},
+		{MethodHead, "/verb/user"},
+		{MethodOptions, "/verb/user"},
+		{MethodOptions, "/verb"},
+	}
+	for _, m := range anyMethods {
+		found = append(found, [2]string{m, "/any"})
+	}
+	for _, f := range found {
+		if _, err := rg.Get(f[0], f[1]); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := rg.Get(MethodWs, "/any"); err == nil {
+		t.Error("Any should not register websocket routes")
+	}
+	if _, err := rg.Get("INDEX", "/verb"); err == nil {
+		t.Error("method without known prefix should not be registered")
+	}
+}
+
+func TestTrimPath(t *testing.T) {
+	cases := map[string]string{
+		"":                     "/",
+		"users//:id/":          "/users/:id",
+		"/codes/{c:[A-Z]{3}}/": "/codes/{c:[A-Z]{3}}",
+		"//a///b":              "/a/b",
+	}
+	for in, want := range cases {
+		if got := trimPath(in); got != want {
+			t.Errorf("trimPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -4,8 +4,13 @@
 package smile
 
 import (
+	"context"
+	"fmt"
+	"html/template"
+	"net"
 	"net/http"
 	"os"
+	"sync"
 )
 
 //Engine 一个服务器引擎
@@ -14,6 +19,14 @@ type Engine struct {
 	engine     		IEngine
 	Logger     		ILogger
 	Gzip       		bool
+	hostGroups		map[string]*RouteGroup //精确匹配的host->路由组
+	hostRoutes		[]*hostRoute           //通配或捕获参数的host 按注册顺序匹配
+	html			*htmlRender            //已加载的HTML模板
+	funcMap			template.FuncMap       //模板函数
+	bodyOptions		*BodyOptions           //默认的请求体解析配置
+	servers			[]*http.Server         //Run/RunTLS启动的服务器
+	cancels			[]context.CancelFunc   //取消每个服务器全部请求的context
+	serverMu		sync.Mutex
 	//debug
 	Errors 			[]error
 }
@@ -25,7 +38,7 @@ func Default() *Engine {
 		engine:     createEngine(false),
 		Logger:     &Logger{os.Stdout, true},
 		Gzip:       true,
-		RouteGroup: new(RouteGroup),
+		RouteGroup: NewRouteGroup(),
 	}
 }
 
@@ -49,8 +62,8 @@ func (e *Engine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	engine := e.engine.Init(cb)
 
 	var err error
-	//路由校验以及404处理
-	engine.Check(e.RouteGroup)
+	//根据host选择路由组 路由校验以及404处理
+	engine.Check(e.routeGroupFor(r.Host, &cb.params))
 
 	//如果已经注册了 并且日志开关开启
 	//则进行日志打印
@@ -90,37 +103,81 @@ func (e *Engine) prepareRun() {
 	if !GetInitState() {
 		DoCustomInit()
 	}
-	doPrintRoutes(e.RouteGroup.FormatRoutes())
+	doPrintRoutes(e.RouteGroup.Routes())
+	for host, rg := range e.hostGroups {
+		fmt.Fprintf(os.Stdout, "[SMILE Route]host %s:\r\n", host)
+		doPrintRoutes(rg.Routes())
+	}
+	for _, h := range e.hostRoutes {
+		fmt.Fprintf(os.Stdout, "[SMILE Route]host %s:\r\n", h.pattern)
+		doPrintRoutes(h.group.Routes())
+	}
 }
 
-//Run 启动一个HttpServer
+//Run 启动一个HttpServer 可以通过Shutdown关闭
 func (e *Engine) Run(port string) (err error) {
 
 	defer doRecover(&err, nil)
 
 	e.prepareRun()
 	
-	err = http.ListenAndServe(port, e)
-	if err != nil {
+	err = e.newServer(port).ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
 		e.Errors = append(e.Errors, err)
 	}
 	return
 }
 
-//RunTLS 启动一个HttpsServer
+//RunTLS 启动一个HttpsServer 可以通过Shutdown关闭
 func (e *Engine) RunTLS(port, cert, key string) (err error) {
 
 	defer doRecover(&err, nil)
 
 	e.prepareRun()
 
-	err = http.ListenAndServeTLS(port, cert, key, e)
-	if err != nil {
+	err = e.newServer(port).ListenAndServeTLS(cert, key)
+	if err != nil && err != http.ErrServerClosed {
 		e.Errors = append(e.Errors, err)
 	}
 	return
 }
 
+//newServer 生成运行服务器使用的http.Server
+//每个请求的context都派生自同一个base context 服务器关闭时一并取消
+func (e *Engine) newServer(addr string) *http.Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     e,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	e.serverMu.Lock()
+	e.servers = append(e.servers, srv)
+	e.cancels = append(e.cancels, cancel)
+	e.serverMu.Unlock()
+	return srv
+}
+
+//Shutdown 优雅关闭全部通过Run/RunTLS启动的服务器
+//立即取消正在处理的请求的context(Context.Done) 不再接受新连接 并等待请求处理结束
+//ctx结束时仍有请求未处理完则返回ctx的错误 关闭后Run/RunTLS返回 http.ErrServerClosed
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.serverMu.Lock()
+	servers, cancels := e.servers, e.cancels
+	e.servers, e.cancels = nil, nil
+	e.serverMu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+	var err error
+	for _, srv := range servers {
+		if serr := srv.Shutdown(ctx); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
 //GetErrors 获取引擎中的错误
 func (e *Engine) GetErrors() []error {
 	return e.Errors
@@ -1,9 +1,13 @@
 package smile
 
 import (
+	"context"
+	"net"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 type smileController struct{}
@@ -41,11 +45,11 @@ func TestSmile(t *testing.T) {
 	e.GzipOn()
 
 	e.SetLoger(&Logger{os.Stdout, true})
-	e.SetRouteGroup(&RouteGroup{
-		GET:  map[string]HandlerFunc{"func": sfc.GetFunc},
-		POST: map[string]HandlerFunc{"func": sfc.PostFunc},
-		WS:   map[string]HandlerFunc{"func": sfc.WsFunc},
-	})
+	rg := NewRouteGroup()
+	rg.SetGET("func", sfc.GetFunc)
+	rg.SetPOST("func", sfc.PostFunc)
+	rg.SetWS("func", sfc.WsFunc)
+	e.SetRouteGroup(rg)
 
 	resp := httptest.NewRecorder()
 	req := httptest.NewRequest("GET", "http://localhost:9999/func", nil)
@@ -88,3 +92,58 @@ func TestMode(t *testing.T) {
 	}
 	t.Log(Mode())
 }
+
+func TestShutdownCancelsRequests(t *testing.T) {
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+	e := Default()
+	e.RouteGroup.SetGET("/slow", func(c *Context) error {
+		close(started)
+		select {
+		case <-c.Done():
+			canceled <- c.Err()
+		case <-time.After(5 * time.Second):
+			canceled <- nil
+		}
+		return nil
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := e.newServer(l.Addr().String())
+	served := make(chan error, 2)
+	go func() { served <- srv.Serve(l) }()
+	//同一引擎启动的第二个服务器也需要一并关闭
+	l2, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv2 := e.newServer(l2.Addr().String())
+	go func() { served <- srv2.Serve(l2) }()
+	go func() {
+		resp, err := http.Get("http://" + l.Addr().String() + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-canceled; err != context.Canceled {
+		t.Errorf("in-flight request context: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-served; err != http.ErrServerClosed {
+			t.Errorf("serve returned %v", err)
+		}
+	}
+	if err := Default().Shutdown(ctx); err != nil {
+		t.Errorf("shutdown without a server: %v", err)
+	}
+}
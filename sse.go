@@ -0,0 +1,128 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//SSEStream 一个Server-Sent Events响应流
+//每次发送后立即刷新到客户端 客户端断开后发送会返回错误
+type SSEStream struct {
+	c  *Context
+	mu sync.Mutex //心跳与业务方法可能同时写入
+}
+
+//SSE 将本次响应转为Server-Sent Events流
+//设置 text/event-stream 等响应头并立即发送 本次响应不使用gzip压缩
+func (c *Context) SSE() *SSEStream {
+	c.gzOff()
+	h := c.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	c.WriteHeader(http.StatusOK)
+	c.End()
+	c.Flush()
+	return &SSEStream{c: c}
+}
+
+//Done 客户端断开或请求结束时关闭
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.c.Request.Context().Done()
+}
+
+//Send 发送一个事件 event及id为空时不发送对应字段
+//data为string或[]byte时原样发送 其他类型编码为JSON 多行数据拆分为多个data字段
+func (s *SSEStream) Send(event, id string, data interface{}) error {
+	var text string
+	switch d := data.(type) {
+	case string:
+		text = d
+	case []byte:
+		text = string(d)
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+	var b strings.Builder
+	if event != "" {
+		b.WriteString("event: " + sseField(event) + "\n")
+	}
+	if id != "" {
+		b.WriteString("id: " + sseField(id) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+//Retry 告知客户端断开后重连的等待时间
+func (s *SSEStream) Retry(d time.Duration) error {
+	return s.write("retry: " + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n")
+}
+
+//Comment 发送一条注释 客户端会忽略注释 可用于保持连接
+func (s *SSEStream) Comment(text string) error {
+	return s.write(": " + sseField(text) + "\n\n")
+}
+
+//Heartbeat 每隔interval发送一条注释保持连接 直到客户端断开或调用返回的stop
+//stop会等待心跳停止 需在处理方法返回前调用 如 defer s.Heartbeat(15*time.Second)()
+func (s *SSEStream) Heartbeat(interval time.Duration) (stop func()) {
+	quit := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if s.Comment("heartbeat") != nil {
+					return
+				}
+			case <-quit:
+				return
+			case <-s.Done():
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(quit) })
+		<-exited
+	}
+}
+
+//write 写入并刷新 客户端已断开时返回断开原因
+func (s *SSEStream) write(msg string) error {
+	if err := s.c.Request.Context().Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.c.Write([]byte(msg)); err != nil {
+		return err
+	}
+	s.c.Flush()
+	return nil
+}
+
+//sseField 去掉字段中的换行 避免破坏事件格式
+func sseField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,65 @@
+package smile
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSE(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/events", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	c := initContext(w, r, Default())
+	s := c.SSE()
+	_ = s.Retry(3 * time.Second)
+	_ = s.Send("message", "1", "line1\nline2")
+	_ = s.Send("", "", map[string]int{"n": 2})
+	_ = s.Comment("ping")
+	c.Close()
+
+	if !w.Flushed {
+		t.Error("stream should be flushed")
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type %q", ct)
+	}
+	if ce := w.Header().Get("Content-Encoding"); ce != "" {
+		t.Errorf("sse response should not be compressed, Content-Encoding %q", ce)
+	}
+	want := "retry: 3000\n\n" +
+		"event: message\nid: 1\ndata: line1\ndata: line2\n\n" +
+		"data: {\"n\":2}\n\n" +
+		": ping\n\n"
+	if w.Body.String() != want {
+		t.Errorf("body %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestSSEHeartbeatAndDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	c := initContext(w, r, Default())
+	s := c.SSE()
+
+	stop := s.Heartbeat(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	stop()
+	if !strings.Contains(w.Body.String(), ": heartbeat\n\n") {
+		t.Errorf("heartbeat not sent: %q", w.Body.String())
+	}
+
+	cancel()
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done should close after the client disconnects")
+	}
+	if err := s.Send("", "", "late"); err == nil {
+		t.Error("send after disconnect should fail")
+	}
+}
@@ -0,0 +1,27 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"io"
+)
+
+//Stream 以流的方式输出响应 用于长时间运行的响应
+//重复调用step写入数据 每次调用后立即刷新到客户端 开启gzip时同样逐次压缩输出
+//step返回false或客户端断开时结束 返回值表示客户端是否已断开
+func (c *Context) Stream(step func(w io.Writer) bool) bool {
+	done := c.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+			keepOpen := step(c.ResponseWriter)
+			c.Flush()
+			if !keepOpen {
+				return false
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package smile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+//flushedGzip 解压已经刷新到客户端的gzip数据 数据流未结束时忽略 io.ErrUnexpectedEOF
+func flushedGzip(t *testing.T, b []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := initContext(w, httptest.NewRequest("GET", "/", nil), Default())
+	c.WriteHeader(202)
+	i := 0
+	gone := c.Stream(func(out io.Writer) bool {
+		if i > 0 && w.Body.String() != fmt.Sprint("chunk", i-1) {
+			t.Errorf("step %d: previous chunk not flushed, body %q", i, w.Body.String())
+		}
+		w.Body.Reset()
+		fmt.Fprint(out, "chunk", i)
+		i++
+		return i < 3
+	})
+	if gone || i != 3 || w.Code != 202 || !w.Flushed {
+		t.Errorf("gone %v steps %d code %d flushed %v", gone, i, w.Code, w.Flushed)
+	}
+}
+
+func TestStreamGzip(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	c := initContext(w, r, Default())
+	want := ""
+	i := 0
+	c.Stream(func(out io.Writer) bool {
+		if i > 0 {
+			if got := flushedGzip(t, w.Body.Bytes()); got != want {
+				t.Errorf("step %d: flushed %q, want %q", i, got, want)
+			}
+		}
+		chunk := fmt.Sprint("chunk", i)
+		fmt.Fprint(out, chunk)
+		want += chunk
+		i++
+		return i < 3
+	})
+	c.Close()
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Error("stream should keep gzip")
+	}
+	if got := flushedGzip(t, w.Body.Bytes()); got != want {
+		t.Errorf("body %q, want %q", got, want)
+	}
+}
+
+func TestStreamDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	c := initContext(w, httptest.NewRequest("GET", "/", nil).WithContext(ctx), Default())
+	steps := 0
+	gone := c.Stream(func(out io.Writer) bool {
+		steps++
+		cancel()
+		return true
+	})
+	if !gone || steps != 1 {
+		t.Errorf("gone %v steps %d", gone, steps)
+	}
+}
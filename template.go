@@ -0,0 +1,156 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+//htmlRender 已加载的HTML模板
+//每个页面模板与公共模板(布局、片段)组成一个独立的模板集合
+//页面之间可以定义同名的模板块 如 {{define "content"}} 互不影响
+type htmlRender struct {
+	glob     func(pattern string) ([]string, error)
+	readFile func(name string) ([]byte, error)
+	pattern  string   //页面模板
+	shared   []string //布局及片段模板
+	funcMap  template.FuncMap
+
+	pages map[string]*template.Template //页面名称 -> 模板集合
+	base  *template.Template            //只包含公共模板的集合
+}
+
+//LoadHTMLGlob 从目录中加载HTML模板
+//pattern匹配页面模板 shared匹配布局及片段模板 公共模板会加入每个页面的模板集合
+//模板名称为文件名 如 c.HTML(200, "index.html", data)
+//页面可以通过 {{define "content"}}...{{end}}{{template "layout.html" .}} 的方式使用布局
+//ModeDEBUG 模式下每次渲染都重新解析模板 其他模式使用加载时解析的结果 解析失败时将会panic
+func (e *Engine) LoadHTMLGlob(pattern string, shared ...string) {
+	e.loadHTML(&htmlRender{glob: filepath.Glob, readFile: os.ReadFile, pattern: pattern, shared: shared})
+}
+
+//LoadHTMLFS 从fs.FS中加载HTML模板 如使用embed.FS 参数规则与 LoadHTMLGlob 相同
+func (e *Engine) LoadHTMLFS(fsys fs.FS, pattern string, shared ...string) {
+	e.loadHTML(&htmlRender{
+		glob:     func(pattern string) ([]string, error) { return fs.Glob(fsys, pattern) },
+		readFile: func(name string) ([]byte, error) { return fs.ReadFile(fsys, name) },
+		pattern:  pattern,
+		shared:   shared,
+	})
+}
+
+//SetFuncMap 设置模板中可以使用的函数 需在加载模板之前设置
+func (e *Engine) SetFuncMap(funcMap template.FuncMap) {
+	e.funcMap = funcMap
+}
+
+func (e *Engine) loadHTML(h *htmlRender) {
+	h.funcMap = e.funcMap
+	pages, base, err := h.parse()
+	if err != nil {
+		panic("load html templates: " + err.Error())
+	}
+	h.pages, h.base = pages, base
+	e.html = h
+}
+
+//parse 解析全部模板
+func (h *htmlRender) parse() (map[string]*template.Template, *template.Template, error) {
+	base := template.New("").Funcs(h.funcMap)
+	sharedFiles := make(map[string]bool)
+	for _, pattern := range h.shared {
+		files, err := h.files(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range files {
+			if err := h.parseFile(base, file); err != nil {
+				return nil, nil, err
+			}
+			sharedFiles[file] = true
+		}
+	}
+	files, err := h.files(h.pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	pages := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		if sharedFiles[file] {
+			continue
+		}
+		t, err := base.Clone()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := h.parseFile(t, file); err != nil {
+			return nil, nil, err
+		}
+		pages[path.Base(filepath.ToSlash(file))] = t
+	}
+	return pages, base, nil
+}
+
+//files 返回匹配的文件 没有匹配的文件时返回错误
+func (h *htmlRender) files(pattern string) ([]string, error) {
+	files, err := h.glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("pattern " + pattern + " matches no files")
+	}
+	return files, nil
+}
+
+//parseFile 以文件名为模板名称 将文件解析到模板集合t中
+func (h *htmlRender) parseFile(t *template.Template, file string) error {
+	b, err := h.readFile(file)
+	if err != nil {
+		return err
+	}
+	_, err = t.New(path.Base(filepath.ToSlash(file))).Parse(string(b))
+	return err
+}
+
+//lookup 根据名称查找模板集合 ModeDEBUG 模式下重新解析
+func (h *htmlRender) lookup(name string) (*template.Template, error) {
+	pages, base := h.pages, h.base
+	if Mode() == ModeDEBUG {
+		var err error
+		if pages, base, err = h.parse(); err != nil {
+			return nil, err
+		}
+	}
+	if t, ok := pages[name]; ok {
+		return t, nil
+	}
+	if base.Lookup(name) != nil {
+		return base, nil
+	}
+	return nil, errors.New("html template " + name + " not found")
+}
+
+//HTML 使用已加载的模板name渲染data 以status状态输出
+//渲染失败时不写入任何响应 返回错误
+func (c *Context) HTML(status int, name string, data interface{}) error {
+	if c.engine == nil || c.engine.html == nil {
+		return errors.New("html templates not loaded")
+	}
+	t, err := c.engine.html.lookup(name)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	return c.Data(status, contentTypeHTML, buf.Bytes())
+}
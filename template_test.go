@@ -0,0 +1,89 @@
+package smile
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func renderHTML(t *testing.T, e *Engine, name string, data interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c := initContext(w, httptest.NewRequest("GET", "/", nil), e)
+	if err := c.HTML(200, name, data); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestLoadHTMLFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/layouts/base.html":  {Data: []byte(`<html>{{template "nav.html" .}}{{block "content" .}}{{end}}</html>`)},
+		"views/partials/nav.html":  {Data: []byte(`<nav>{{.User | upper}}</nav>`)},
+		"views/pages/index.html":   {Data: []byte(`{{define "content"}}<p>home {{.Msg}}</p>{{end}}{{template "base.html" .}}`)},
+		"views/pages/profile.html": {Data: []byte(`{{define "content"}}<p>profile</p>{{end}}{{template "base.html" .}}`)},
+	}
+	e := Default()
+	e.SetFuncMap(template.FuncMap{"upper": strings.ToUpper})
+	e.LoadHTMLFS(fsys, "views/pages/*.html", "views/layouts/*.html", "views/partials/*.html")
+
+	data := map[string]string{"User": "tom", "Msg": "<b>"}
+	w := renderHTML(t, e, "index.html", data)
+	if want := `<html><nav>TOM</nav><p>home &lt;b&gt;</p></html>`; w.Body.String() != want {
+		t.Errorf("index: %q, want %q", w.Body.String(), want)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != contentTypeHTML {
+		t.Errorf("content type %q", ct)
+	}
+	if w := renderHTML(t, e, "profile.html", data); w.Body.String() != `<html><nav>TOM</nav><p>profile</p></html>` {
+		t.Errorf("profile: %q", w.Body.String())
+	}
+	if w := renderHTML(t, e, "nav.html", data); w.Body.String() != `<nav>TOM</nav>` {
+		t.Errorf("partial: %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c := initContext(w, httptest.NewRequest("GET", "/", nil), e)
+	if err := c.HTML(200, "missing.html", nil); err == nil || w.Body.Len() != 0 {
+		t.Errorf("missing template: %v %q", err, w.Body.String())
+	}
+	if err := c.HTML(200, "nav.html", struct{}{}); err == nil || w.Body.Len() != 0 {
+		t.Errorf("execute error should not write a partial response: %v %q", err, w.Body.String())
+	}
+}
+
+func TestLoadHTMLPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("pattern without files should panic")
+		}
+	}()
+	Default().LoadHTMLFS(fstest.MapFS{}, "*.html")
+}
+
+func TestLoadHTMLGlobReload(t *testing.T) {
+	defer func(m string) { mode = m }(mode)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "index.html")
+	write := func(s string) {
+		if err := os.WriteFile(file, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("v1")
+	e := Default()
+	e.LoadHTMLGlob(filepath.Join(dir, "*.html"))
+	write("v2")
+
+	SetPRODUCTION()
+	if w := renderHTML(t, e, "index.html", nil); w.Body.String() != "v1" {
+		t.Errorf("production should use cached templates, got %q", w.Body.String())
+	}
+	SetDEBUG()
+	if w := renderHTML(t, e, "index.html", nil); w.Body.String() != "v2" {
+		t.Errorf("debug should re-parse templates, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,279 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"errors"
+	"strings"
+)
+
+//路径参数的前缀标识 必须出现在一段路径的开头
+const (
+	paramPrefix    = ':' //命名参数 匹配一段路径 如 /users/:id
+	catchAllPrefix = '*' //通配参数 匹配剩余全部路径 只能出现在路由末尾 如 /assets/*filepath
+	braceOpen      = '{' //带约束的命名参数 如 /orders/{id:[0-9]+} /orders/{id:int}
+	braceClose     = '}'
+)
+
+//节点类型 同一节点下的匹配优先级为 静态 > 命名参数 > 通配参数
+type nodeKind uint8
+
+const (
+	nodeStatic nodeKind = iota
+	nodeParam
+	nodeCatchAll
+)
+
+//node 压缩前缀树(radix tree)的节点
+//静态节点保存公共前缀 参数节点保存参数名
+type node struct {
+	kind     nodeKind
+	path     string  //静态节点为路径片段 参数节点为参数名
+	expr     string  //参数节点的约束表达式 为空时不做约束
+	check    func(string) bool
+	pattern  string  //参数节点首次注册时的完整路由 用于冲突提示
+	indices  string  //静态子节点的首字节 与children一一对应
+	children []*node //静态子节点
+	params   []*node //命名参数子节点
+	catchAll *node   //通配参数子节点
+	route    *Route  //该节点上注册的路由
+}
+
+//addRoute 向树中添加一条路由
+//同一位置出现不同名的参数 或者路由重复注册时 返回错误
+func (n *node) addRoute(pattern string, r *Route) error {
+	cur := n
+	path := pattern
+	for {
+		i := wildcardIndex(path)
+		if i < 0 {
+			cur = cur.insertStatic(path)
+			break
+		}
+		cur = cur.insertStatic(path[:i])
+		path = path[i:]
+		name, expr, end, catchAll, err := parseWildcard(path)
+		if err != nil {
+			return errors.New("route " + pattern + ": " + err.Error())
+		}
+		if catchAll {
+			if end != len(path) {
+				return errors.New("route " + pattern + ": catch-all parameter must be at the end of the path")
+			}
+			if cur.catchAll == nil {
+				cur.catchAll = &node{kind: nodeCatchAll, path: name, pattern: pattern}
+			} else if cur.catchAll.path != name {
+				return errors.New("route " + pattern + ": catch-all *" + name +
+					" conflicts with *" + cur.catchAll.path + " in existing route " + cur.catchAll.pattern)
+			}
+			cur = cur.catchAll
+			break
+		}
+		child, err := cur.paramChild(name, expr, pattern)
+		if err != nil {
+			return err
+		}
+		cur = child
+		path = path[end:]
+		if path == "" {
+			break
+		}
+	}
+	if cur.route != nil {
+		return errors.New("route " + pattern + ": already registered as " + cur.route.pattern)
+	}
+	cur.route = r
+	return nil
+}
+
+//paramChild 获取或创建一个命名参数子节点
+//约束相同而参数名不同时视为冲突 带约束的参数先于不带约束的参数匹配
+func (n *node) paramChild(name, expr, pattern string) (*node, error) {
+	for _, p := range n.params {
+		if p.expr != expr {
+			continue
+		}
+		if p.path == name {
+			return p, nil
+		}
+		return nil, errors.New("route " + pattern + ": parameter " + name +
+			" conflicts with " + p.path + " in existing route " + p.pattern)
+	}
+	check, err := newParamCheck(expr)
+	if err != nil {
+		return nil, errors.New("route " + pattern + ": " + err.Error())
+	}
+	child := &node{kind: nodeParam, path: name, expr: expr, check: check, pattern: pattern}
+	i := len(n.params)
+	if expr != "" {
+		for i > 0 && n.params[i-1].expr == "" {
+			i--
+		}
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[i+1:], n.params[i:])
+	n.params[i] = child
+	return child, nil
+}
+
+//insertStatic 插入一段静态路径 必要时拆分已有节点 返回路径末端的节点
+func (n *node) insertStatic(path string) *node {
+	for path != "" {
+		idx := strings.IndexByte(n.indices, path[0])
+		if idx < 0 {
+			child := &node{kind: nodeStatic, path: path}
+			n.indices += path[:1]
+			n.children = append(n.children, child)
+			return child
+		}
+		child := n.children[idx]
+		l := commonPrefixLen(path, child.path)
+		if l < len(child.path) {
+			//公共前缀短于已有节点 拆分为 前缀节点 + 剩余节点
+			rest := *child
+			rest.path = child.path[l:]
+			*child = node{
+				kind:     nodeStatic,
+				path:     child.path[:l],
+				indices:  rest.path[:1],
+				children: []*node{&rest},
+			}
+		}
+		path = path[l:]
+		n = child
+	}
+	return n
+}
+
+//getValue 在树中查找路径对应的路由
+//匹配到的参数追加到ps中 查找失败时ps恢复原长度
+//静态路由的查找不产生内存分配
+func (n *node) getValue(path string, ps *Params) *Route {
+	switch n.kind {
+	case nodeStatic:
+		if !strings.HasPrefix(path, n.path) {
+			//路径规范化后没有结尾的 / 此时 /assets 也可以匹配 /assets/*filepath
+			if n.catchAll != nil && len(path)+1 == len(n.path) &&
+				n.path[len(path)] == '/' && strings.HasPrefix(n.path, path) {
+				*ps = append(*ps, Param{Key: n.catchAll.path})
+				return n.catchAll.route
+			}
+			return nil
+		}
+		path = path[len(n.path):]
+	case nodeParam:
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end == 0 || (n.check != nil && !n.check(path[:end])) {
+			return nil
+		}
+		*ps = append(*ps, Param{Key: n.path, Value: path[:end]})
+		path = path[end:]
+	case nodeCatchAll:
+		*ps = append(*ps, Param{Key: n.path, Value: path})
+		return n.route
+	}
+
+	if path == "" {
+		if n.route != nil {
+			return n.route
+		}
+		//静态节点被其他路由拆分 或位于参数之后时 通配参数挂在 / 子节点上
+		//如 /assets 匹配 /assets/*filepath /u/1 匹配 /u/:id/*rest
+		if idx := strings.IndexByte(n.indices, '/'); idx >= 0 {
+			if child := n.children[idx]; child.path == "/" && child.catchAll != nil {
+				*ps = append(*ps, Param{Key: child.catchAll.path})
+				return child.catchAll.route
+			}
+		}
+	}
+	mark := len(*ps)
+	if path != "" {
+		if idx := strings.IndexByte(n.indices, path[0]); idx >= 0 {
+			if r := n.children[idx].getValue(path, ps); r != nil {
+				return r
+			}
+			*ps = (*ps)[:mark]
+		}
+		for _, p := range n.params {
+			if r := p.getValue(path, ps); r != nil {
+				return r
+			}
+			*ps = (*ps)[:mark]
+		}
+	}
+	if n.catchAll != nil {
+		return n.catchAll.getValue(path, ps)
+	}
+	return nil
+}
+
+//wildcardIndex 返回路径中第一个参数标识的位置 参数必须位于一段路径的开头
+func wildcardIndex(path string) int {
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case paramPrefix, catchAllPrefix, braceOpen:
+			if i == 0 || path[i-1] == '/' {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+//parseWildcard 解析以参数标识开头的一段路径
+//返回参数名、约束表达式、参数片段的长度以及是否为通配参数
+func parseWildcard(path string) (name, expr string, end int, catchAll bool, err error) {
+	if path[0] != braceOpen {
+		end = strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		name = path[1:end]
+		if name == "" {
+			return "", "", 0, false, errors.New("parameter must have a name")
+		}
+		return name, "", end, path[0] == catchAllPrefix, nil
+	}
+	//约束表达式中可以出现成对的 {} 如 {code:[a-z]{3}}
+	depth := 0
+	for end = 0; end < len(path); end++ {
+		if path[end] == braceOpen {
+			depth++
+		} else if path[end] == braceClose {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	if end == len(path) {
+		return "", "", 0, false, errors.New("unclosed parameter " + path)
+	}
+	name = path[1:end]
+	if idx := strings.IndexByte(name, ':'); idx >= 0 {
+		name, expr = name[:idx], name[idx+1:]
+	}
+	end++
+	switch {
+	case name == "":
+		err = errors.New("parameter must have a name")
+	case end < len(path) && path[end] != '/':
+		err = errors.New("parameter " + name + " must end a path segment")
+	case strings.IndexByte(expr, '/') >= 0:
+		err = errors.New("constraint of parameter " + name + " must not contain /")
+	}
+	return name, expr, end, false, err
+}
+
+//commonPrefixLen 两个字符串公共前缀的长度
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
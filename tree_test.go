@@ -0,0 +1,163 @@
+package smile
+
+import (
+	"testing"
+)
+
+func TestTreeAddRouteConflicts(t *testing.T) {
+	cases := []struct {
+		existing []string
+		pattern  string
+		conflict bool
+	}{
+		{[]string{"/users/:id"}, "/users/:name", true},
+		{[]string{"/users/:id"}, "/users/:id/books", false},
+		{[]string{"/users/:id"}, "/users/new", false},
+		{[]string{"/users/:id"}, "/users/:id", true},
+		{[]string{"/files/*path"}, "/files/*name", true},
+		{[]string{"/files/*path"}, "/files/:name", false},
+		{nil, "/files/*path/more", true},
+		{nil, "/users/:", true},
+		{nil, "/v1:batch", false},
+	}
+	for _, cs := range cases {
+		root := &node{}
+		for _, p := range cs.existing {
+			if err := root.addRoute(p, &Route{pattern: p}); err != nil {
+				t.Fatalf("%s: %v", p, err)
+			}
+		}
+		err := root.addRoute(cs.pattern, &Route{pattern: cs.pattern})
+		if (err != nil) != cs.conflict {
+			t.Errorf("%v + %s: err=%v, want conflict=%v", cs.existing, cs.pattern, err, cs.conflict)
+		}
+	}
+}
+
+func TestTreeGetValue(t *testing.T) {
+	patterns := []string{
+		"/",
+		"/users",
+		"/users/new",
+		"/users/:id",
+		"/users/:id/books",
+		"/users/:id/books/:book",
+		"/usage",
+		"/files/:name",
+		"/files/*path",
+		"/static/*filepath",
+		"/v1:batch",
+		"/assets/*filepath",
+		"/assets-v1",
+		"/u/:id/*rest",
+	}
+	root := &node{}
+	for _, p := range patterns {
+		if err := root.addRoute(p, &Route{pattern: p}); err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+	}
+	cases := []struct {
+		path    string
+		pattern string
+		params  string
+	}{
+		{"/", "/", ""},
+		{"/users", "/users", ""},
+		{"/users/new", "/users/new", ""},
+		{"/users/newer", "/users/:id", "id=newer"},
+		{"/users/7", "/users/:id", "id=7"},
+		{"/users/7/books", "/users/:id/books", "id=7"},
+		{"/users/7/books/go", "/users/:id/books/:book", "id=7,book=go"},
+		{"/usage", "/usage", ""},
+		{"/files/a.txt", "/files/:name", "name=a.txt"},
+		{"/files/a/b.txt", "/files/*path", "path=a/b.txt"},
+		{"/static", "/static/*filepath", "filepath="},
+		{"/static/css/a.css", "/static/*filepath", "filepath=css/a.css"},
+		{"/v1:batch", "/v1:batch", ""},
+		{"/assets", "/assets/*filepath", "filepath="},
+		{"/assets/app.js", "/assets/*filepath", "filepath=app.js"},
+		{"/assets-v1", "/assets-v1", ""},
+		{"/u/1", "/u/:id/*rest", "id=1,rest="},
+		{"/u/1/a/b", "/u/:id/*rest", "id=1,rest=a/b"},
+		{"/use", "", ""},
+		{"/users/7/authors", "", ""},
+	}
+	for _, cs := range cases {
+		var ps Params
+		r := root.getValue(cs.path, &ps)
+		if r == nil {
+			if cs.pattern != "" {
+				t.Errorf("%s: not found, want %s", cs.path, cs.pattern)
+			}
+			continue
+		}
+		if r.pattern != cs.pattern {
+			t.Errorf("%s: matched %s, want %s", cs.path, r.pattern, cs.pattern)
+		}
+		got := ""
+		for i, p := range ps {
+			if i > 0 {
+				got += ","
+			}
+			got += p.Key + "=" + p.Value
+		}
+		if got != cs.params {
+			t.Errorf("%s: params %q, want %q", cs.path, got, cs.params)
+		}
+	}
+}
+
+func TestTreeParamConstraints(t *testing.T) {
+	patterns := []string{
+		"/orders/new",
+		"/orders/:any",
+		"/orders/{id:int}",
+		"/orders/{slug:[a-z-]+}",
+		"/orders/{id:int}/items",
+		"/users/{uid:uuid}",
+		"/days/{day:date}",
+		"/codes/{code:[A-Z]{3}}",
+	}
+	root := &node{}
+	for _, p := range patterns {
+		if err := root.addRoute(p, &Route{pattern: p}); err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+	}
+	cases := []struct {
+		path    string
+		pattern string
+	}{
+		{"/orders/new", "/orders/new"},
+		{"/orders/42", "/orders/{id:int}"},
+		{"/orders/-3", "/orders/{id:int}"},
+		{"/orders/big-sale", "/orders/{slug:[a-z-]+}"},
+		{"/orders/Big_Sale", "/orders/:any"},
+		{"/orders/42/items", "/orders/{id:int}/items"},
+		{"/orders/abc/items", ""},
+		{"/users/123e4567-e89b-12d3-a456-426614174000", "/users/{uid:uuid}"},
+		{"/users/123", ""},
+		{"/days/2020-02-29", "/days/{day:date}"},
+		{"/days/2021-02-29", ""},
+		{"/codes/ABC", "/codes/{code:[A-Z]{3}}"},
+		{"/codes/ABCD", ""},
+	}
+	for _, cs := range cases {
+		var ps Params
+		r := root.getValue(cs.path, &ps)
+		got := ""
+		if r != nil {
+			got = r.pattern
+		}
+		if got != cs.pattern {
+			t.Errorf("%s: matched %q, want %q", cs.path, got, cs.pattern)
+		}
+	}
+
+	for _, p := range []string{"/orders/{num:int}", "/orders/{id", "/orders/{id:[0-9]+}x", "/orders/{id:(}", "/orders/{:int}"} {
+		if err := root.addRoute(p, &Route{pattern: p}); err == nil {
+			t.Errorf("%s: expected error", p)
+		}
+	}
+}
@@ -15,24 +15,43 @@ func getFuncName(i interface{}) string {
 }
 
 //trimPath format path in one entrance
+//repeated slashes are merged, while parameter constraints in braces are kept as they are
 func trimPath(path string) string {
-	return "/" + strings.Trim(path, "/")
-}
-
-func doPrintRoutes(routesAssign []string, routesAutoCreate []string) {
-	if len(routesAssign) > 0{
-		fmt.Fprintf(os.Stdout, "[SMILE Route]%s\r\n", "assigned:")
-		for _, v := range routesAssign {
-			fmt.Fprintf(os.Stdout, "[SMILE Route]%s\r\n", v)
-		}
+	path = strings.Trim(path, "/")
+	if !strings.Contains(path, "//") {
+		return "/" + path
 	}
-	if len(routesAutoCreate) > 0 {
-		fmt.Fprintf(os.Stdout, "[SMILE Route]%s\r\n", "autocreated:")
-		for _, v := range routesAutoCreate {
-			fmt.Fprintf(os.Stdout, "[SMILE Route]%s\r\n", v)
+	b := make([]byte, 0, len(path)+1)
+	b = append(b, '/')
+	depth := 0
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case braceOpen:
+			depth++
+		case braceClose:
+			depth--
+		case '/':
+			if depth == 0 && b[len(b)-1] == '/' {
+				continue
+			}
 		}
+		b = append(b, path[i])
 	}
-	if len(routesAssign) == 0 && len(routesAutoCreate) == 0 {
+	return string(b)
+}
+
+//doPrintRoutes print the route table sorted by path and method
+func doPrintRoutes(routes []RouteInfo) {
+	if len(routes) == 0 {
 		fmt.Fprintf(os.Stdout, "[SMILE Route]%s\r\n", "No Route Registered!")
+		return
+	}
+	width := routesPathWidth(routes)
+	for _, info := range routes {
+		s := info.format(width)
+		if info.AutoFilled {
+			s += " [auto]"
+		}
+		fmt.Fprintf(os.Stdout, "[SMILE Route]%s\r\n", s)
 	}
 }
@@ -0,0 +1,286 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//FieldError 一个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`           //字段路径 如 items[0].name 优先使用json标签名
+	Rule    string `json:"rule"`            //未通过的规则
+	Param   string `json:"param,omitempty"` //规则参数
+	Message string `json:"message"`         //错误说明
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+//ValidationErrors 结构体校验未通过时返回的全部字段错误
+type ValidationErrors []*FieldError
+
+func (es ValidationErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+//Validate 按validate标签校验结构体 未通过时返回 ValidationErrors
+//规则之间使用 , 分隔 支持
+//	required     不能为零值
+//	omitempty    为零值时不再校验其他规则
+//	min=n max=n  数字的取值范围 字符串的字符数 切片及map的元素个数
+//	len=n        数字等于n 字符串的字符数或切片及map的元素个数等于n
+//	email        字符串为邮箱地址
+//	oneof=a b c  取值为列出的值之一
+//	regexp=expr  字符串匹配正则 必须为最后一条规则 表达式中可以包含 ,
+//	dive         之后的规则作用于切片或map的每个元素
+//零值同样按规则校验 如 min=1 不接受0 可选字段请使用omitempty
+//未设置required的nil指针不再校验 结构体字段会递归校验
+//标签书写错误时将会panic
+func Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs ValidationErrors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+//validateRule 一条校验规则
+type validateRule struct {
+	name  string
+	param string
+}
+
+//parseRules 解析validate标签 regexp规则之后的内容全部作为正则表达式
+func parseRules(tag string) []validateRule {
+	var rules []validateRule
+	for tag != "" {
+		var item string
+		if strings.HasPrefix(tag, "regexp=") {
+			item, tag = tag, ""
+		} else if idx := strings.IndexByte(tag, ','); idx >= 0 {
+			item, tag = tag[:idx], tag[idx+1:]
+		} else {
+			item, tag = tag, ""
+		}
+		rule := validateRule{name: item}
+		if idx := strings.IndexByte(item, '='); idx >= 0 {
+			rule.name, rule.param = item[:idx], item[idx+1:]
+		}
+		if rule.name == "" {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+//validateStruct 依次校验结构体的每个字段
+func validateStruct(sv reflect.Value, path string, errs *ValidationErrors) {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateStruct(sv.Field(i), path, errs)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		validateValue(sv.Field(i), joinField(path, fieldName(field)), parseRules(tag), errs)
+	}
+}
+
+//validateValue 校验一个值 dive之前的规则作用于值本身 之后的规则作用于每个元素
+func validateValue(v reflect.Value, path string, rules []validateRule, errs *ValidationErrors) {
+	var elemRules []validateRule
+	dive := false
+	for i, rule := range rules {
+		if rule.name == "dive" {
+			rules, elemRules, dive = rules[:i], rules[i+1:], true
+			break
+		}
+	}
+	required, omitempty := false, false
+	for _, rule := range rules {
+		switch rule.name {
+		case "required":
+			required = true
+		case "omitempty":
+			omitempty = true
+		}
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if required {
+				*errs = append(*errs, &FieldError{Field: path, Rule: "required", Message: "is required"})
+			}
+			return
+		}
+		v = v.Elem()
+	}
+
+	if v.IsZero() {
+		if required {
+			*errs = append(*errs, &FieldError{Field: path, Rule: "required", Message: "is required"})
+			return
+		}
+		if omitempty {
+			return
+		}
+	}
+	for _, rule := range rules {
+		if rule.name == "required" || rule.name == "omitempty" {
+			continue
+		}
+		if msg := checkRule(v, rule); msg != "" {
+			*errs = append(*errs, &FieldError{Field: path, Rule: rule.name, Param: rule.param, Message: msg})
+		}
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct:
+		validateStruct(v, path, errs)
+	case dive && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array):
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), path+"["+strconv.Itoa(i)+"]", elemRules, errs)
+		}
+	case dive && v.Kind() == reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			validateValue(iter.Value(), path+"["+fmt.Sprint(iter.Key().Interface())+"]", elemRules, errs)
+		}
+	case dive:
+		panic("validate " + path + ": dive used on " + v.Type().String())
+	}
+}
+
+//checkRule 校验一条规则 通过时返回空字符串 否则返回错误说明
+func checkRule(v reflect.Value, rule validateRule) string {
+	switch rule.name {
+	case "min", "max", "len":
+		n, err := strconv.ParseFloat(rule.param, 64)
+		if err != nil {
+			panic("validate: invalid param " + strconv.Quote(rule.param) + " for rule " + rule.name)
+		}
+		size, unit := validateSize(v, rule.name)
+		switch {
+		case rule.name == "min" && size < n:
+			return "must be at least " + rule.param + unit
+		case rule.name == "max" && size > n:
+			return "must be at most " + rule.param + unit
+		case rule.name == "len" && size != n:
+			return "must be exactly " + rule.param + unit
+		}
+	case "email":
+		if !emailPattern.MatchString(validateString(v, rule.name)) {
+			return "must be a valid email address"
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(rule.param) {
+			if s == option {
+				return ""
+			}
+		}
+		return "must be one of [" + rule.param + "]"
+	case "regexp":
+		if !compileRule(rule.param).MatchString(validateString(v, rule.name)) {
+			return "must match " + rule.param
+		}
+	default:
+		panic("validate: unknown rule " + rule.name)
+	}
+	return ""
+}
+
+//validateSize 返回用于min/max/len比较的大小及错误说明中的单位
+func validateSize(v reflect.Value, rule string) (float64, string) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), ""
+	}
+	panic("validate: rule " + rule + " not supported on " + v.Type().String())
+}
+
+//validateString 返回用于email/regexp校验的字符串
+func validateString(v reflect.Value, rule string) string {
+	if v.Kind() != reflect.String {
+		panic("validate: rule " + rule + " not supported on " + v.Type().String())
+	}
+	return v.String()
+}
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s.]+$`)
+	//regexp规则编译后的缓存
+	rulePatterns sync.Map
+)
+
+//compileRule 编译regexp规则的表达式 表达式需完整匹配
+func compileRule(expr string) *regexp.Regexp {
+	if re, ok := rulePatterns.Load(expr); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic("validate: invalid regexp " + expr + ": " + err.Error())
+	}
+	rulePatterns.Store(expr, re)
+	return re
+}
+
+//fieldName 字段在错误路径中的名称 优先使用json标签名
+func fieldName(field reflect.StructField) string {
+	name := field.Tag.Get("json")
+	if idx := strings.IndexByte(name, ','); idx >= 0 {
+		name = name[:idx]
+	}
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
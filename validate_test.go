@@ -0,0 +1,115 @@
+package smile
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testValidateItem struct {
+	SKU string `json:"sku" validate:"required,regexp=[A-Z]{2}-[0-9]{1,3}"`
+	Qty int    `json:"qty" validate:"min=1,max=99"`
+}
+
+type testValidateOrder struct {
+	Name    string             `json:"name" validate:"required,min=2,max=5"`
+	Email   string             `json:"email" validate:"email"`
+	Status  string             `validate:"oneof=new paid"`
+	Code    string             `json:"code,omitempty" validate:"len=4"`
+	Phone   string             `json:"phone" validate:"omitempty,len=11"`
+	Note    *string            `json:"note" validate:"required"`
+	Items   []testValidateItem `json:"items" validate:"required,max=3,dive"`
+	Tags    []string           `json:"tags" validate:"dive,min=2"`
+	Pattern string             `validate:"regexp=a,b|c{1,2}"`
+	Skip    string             `validate:"-"`
+}
+
+func TestValidate(t *testing.T) {
+	note := "n"
+	ok := testValidateOrder{
+		Name:    "tom",
+		Email:   "tom@example.com",
+		Status:  "paid",
+		Code:    "abcd",
+		Note:    &note,
+		Items:   []testValidateItem{{SKU: "AB-1", Qty: 2}},
+		Tags:    []string{"go", "web"},
+		Pattern: "a,b",
+	}
+	if err := Validate(&ok); err != nil {
+		t.Errorf("valid struct: %v", err)
+	}
+
+	bad := testValidateOrder{
+		Name:    "a",
+		Email:   "tom@",
+		Status:  "lost",
+		Code:    "abc",
+		Items:   []testValidateItem{{SKU: "ab-1"}, {SKU: "AB-2", Qty: 100}},
+		Tags:    []string{"go", "x"},
+		Pattern: "cc",
+	}
+	err := Validate(bad)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("want ValidationErrors, got %v", err)
+	}
+	var got []string
+	for _, e := range verrs {
+		got = append(got, e.Field+":"+e.Rule)
+	}
+	want := "name:min,email:email,Status:oneof,code:len,note:required,items[0].sku:regexp,items[0].qty:min,items[1].qty:max,tags[1]:min"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got  %s\nwant %s", strings.Join(got, ","), want)
+	}
+	if verrs[0].Error() != "name must be at least 2 characters" {
+		t.Errorf("message %q", verrs[0].Error())
+	}
+
+	if err := Validate(&testValidateOrder{}); err == nil || !strings.Contains(err.Error(), "items is required") {
+		t.Errorf("required: %v", err)
+	}
+
+	//零值同样需要满足规则 只有omitempty时跳过
+	zero := struct {
+		ID   int    `validate:"min=1"`
+		Code string `validate:"len=4"`
+		Kind string `validate:"oneof=a b"`
+		Opt  int    `validate:"omitempty,min=1"`
+	}{}
+	got = got[:0]
+	if errors.As(Validate(&zero), &verrs) {
+		for _, e := range verrs {
+			got = append(got, e.Field+":"+e.Rule)
+		}
+	}
+	if strings.Join(got, ",") != "ID:min,Code:len,Kind:oneof" {
+		t.Errorf("zero values: %v", got)
+	}
+}
+
+func TestValidateBadTag(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("unknown rule should panic")
+		}
+	}()
+	_ = Validate(&struct {
+		Name string `validate:"uppercase"`
+	}{Name: "x"})
+}
+
+func TestBindValidate(t *testing.T) {
+	var v struct {
+		Name string `json:"name" validate:"required"`
+		Page int    `query:"page" validate:"max=10"`
+	}
+	c := newBindContext("POST", "/?page=20", "application/json", `{}`)
+	var verrs ValidationErrors
+	if err := c.BindJSON(&v); !errors.As(err, &verrs) || len(verrs) != 1 {
+		t.Errorf("json: %v", err)
+	}
+	if err := c.BindQuery(&v); !errors.As(err, &verrs) || len(verrs) != 2 {
+		t.Errorf("query: %v", err)
+	}
+}
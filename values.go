@@ -0,0 +1,66 @@
+//This software is licensed under the MIT License.
+//You can get more info in license file.
+
+package smile
+
+import (
+	"context"
+	"strconv"
+)
+
+//valuesContext 将Context中保存的键值暴露给 Request.Context()
+//只接收context.Context的代码可以通过 ctx.Value(key) 取得 Context.Set 保存的值
+type valuesContext struct {
+	context.Context
+	c *Context
+}
+
+func (v *valuesContext) Value(key interface{}) interface{} {
+	if k, ok := key.(string); ok {
+		if value, exists := v.c.Get(k); exists {
+			return value
+		}
+	}
+	return v.Context.Value(key)
+}
+
+//Set 保存本次请求范围内的键值 如中间件保存的登录用户、租户、trace id
+//保存的值同时可以通过 Request.Context().Value(key) 获取
+func (c *Context) Set(key string, value interface{}) {
+	c.mu.Lock()
+	if c.keys == nil {
+		c.keys = make(map[string]interface{}, 4)
+	}
+	c.keys[key] = value
+	c.mu.Unlock()
+}
+
+//Get 获取Set保存的值 不存在时exists为false
+func (c *Context) Get(key string) (value interface{}, exists bool) {
+	c.mu.RLock()
+	value, exists = c.keys[key]
+	c.mu.RUnlock()
+	return
+}
+
+//MustGet 获取Set保存的值 不存在时将会panic
+func (c *Context) MustGet(key string) interface{} {
+	if value, exists := c.Get(key); exists {
+		return value
+	}
+	panic("key " + strconv.Quote(key) + " does not exist")
+}
+
+//GetString 获取Set保存的字符串 不存在或不是字符串时返回空字符串
+func (c *Context) GetString(key string) string {
+	value, _ := c.Get(key)
+	s, _ := value.(string)
+	return s
+}
+
+//GetInt 获取Set保存的整数 不存在或不是int时返回0
+func (c *Context) GetInt(key string) int {
+	value, _ := c.Get(key)
+	n, _ := value.(int)
+	return n
+}
@@ -0,0 +1,76 @@
+package smile
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+type testCtxKey struct{}
+
+func TestContextValues(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), testCtxKey{}, "parent"))
+	c := initContext(httptest.NewRecorder(), r, Default())
+
+	if _, ok := c.Get("user"); ok {
+		t.Error("empty store should not contain keys")
+	}
+	//Set不替换Request 后台goroutine读取Request时不会产生竞争
+	req := c.Request
+	c.Set("user", "tom")
+	if c.Request != req {
+		t.Error("Set should not replace the request")
+	}
+	c.Set("uid", 7)
+	if v, ok := c.Get("user"); !ok || v != "tom" {
+		t.Errorf("Get = %v, %v", v, ok)
+	}
+	if c.GetString("user") != "tom" || c.GetInt("uid") != 7 || c.GetString("uid") != "" || c.GetInt("missing") != 0 {
+		t.Error("typed getters")
+	}
+	if c.MustGet("uid") != 7 {
+		t.Error("MustGet")
+	}
+
+	//只接收context.Context的代码同样可以取得保存的值
+	ctx := c.Request.Context()
+	c.Set("tenant", "acme")
+	if ctx.Value("user") != "tom" || ctx.Value("tenant") != "acme" || ctx.Value(testCtxKey{}) != "parent" || ctx.Value("missing") != nil {
+		t.Errorf("request context values: %v %v %v", ctx.Value("user"), ctx.Value("tenant"), ctx.Value(testCtxKey{}))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGet on a missing key should panic")
+		}
+	}()
+	c.MustGet("missing")
+}
+
+func TestContextValuesMiddleware(t *testing.T) {
+	tenantOf := func(ctx context.Context) string {
+		s, _ := ctx.Value("tenant").(string)
+		return s
+	}
+	rg := NewRouteGroup()
+	rg.SetMiddleware(func(c *Context) error {
+		c.Set("tenant", c.GetHeader().Get("X-Tenant"))
+		return nil
+	})
+	rg.SetGET("/", func(c *Context) error {
+		return c.Text(200, tenantOf(c.Request.Context()))
+	})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Tenant", "acme")
+	c := initContext(w, r, Default())
+	engine := createEngine(false).Init(c)
+	engine.Check(rg)
+	if err := engine.Handle(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Body.String() != "acme" {
+		t.Errorf("body %q", w.Body.String())
+	}
+}